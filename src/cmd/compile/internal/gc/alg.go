@@ -61,7 +61,9 @@ func algtype1(t *Type, bad **Type) int {
 	switch t.Etype {
 	case TANY, TFORW:
 		// will be defined later.
-		*bad = t
+		if bad != nil {
+			*bad = t
+		}
 		return -1
 
 	case TINT8, TUINT8, TINT16, TUINT16,
@@ -93,7 +95,7 @@ func algtype1(t *Type, bad **Type) int {
 		return ASTRING
 
 	case TINTER:
-		if isnilinter(t) {
+		if t.IsEmptyInterface() {
 			return ANILINTER
 		}
 		return AINTER
@@ -158,6 +160,28 @@ func algtype1(t *Type, bad **Type) int {
 	return 0
 }
 
+// incomparablefield returns the path of field names leading from
+// struct type t to the field that makes t incomparable, and the type
+// of that field. It descends through nested struct fields; an array
+// of incomparable elements is reported as a whole.
+func incomparablefield(t *Type) (path []*Sym, bad *Type) {
+	for t.Etype == TSTRUCT {
+		var next *Field
+		for _, f := range t.FieldSlice() {
+			if algtype1(f.Type, nil) == ANOEQ {
+				next = f
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		path = append(path, next.Sym)
+		t = next.Type
+	}
+	return path, t
+}
+
 // Generate a helper function to compute the hash of a value of type t.
 func genhash(sym *Sym, t *Type) {
 	if Debug['r'] != 0 {
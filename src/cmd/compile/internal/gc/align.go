@@ -130,6 +130,11 @@ func dowidth(t *Type) {
 		return
 	}
 
+	// zero-sized type, already computed
+	if widthSettled(t) {
+		return
+	}
+
 	// defer checkwidth calls until after we're done
 	defercalc++
 
@@ -335,6 +340,12 @@ func dowidth(t *Type) {
 
 var deferredTypeStack []*Type
 
+// widthSettled reports whether t's width has been computed and is
+// not waiting on a deferred checkwidth.
+func widthSettled(t *Type) bool {
+	return t.Width != BADWIDTH && !t.Deferwidth
+}
+
 func checkwidth(t *Type) {
 	if t == nil {
 		return
@@ -346,6 +357,11 @@ func checkwidth(t *Type) {
 		Fatalf("checkwidth %v", t)
 	}
 
+	// nothing to do if the width is already known.
+	if widthSettled(t) {
+		return
+	}
+
 	if defercalc == 0 {
 		dowidth(t)
 		return
@@ -666,7 +666,7 @@ func (p *exporter) param(q *Field, n int, numbered bool) {
 	t := q.Type
 	if q.Isddd {
 		// create a fake type to encode ... just for the p.typ call
-		t = &Type{Etype: TDDDFIELD, Type: t.Type}
+		t = &Type{Etype: TDDDFIELD, Type: t.Type, Width: BADWIDTH}
 	}
 	p.typ(t)
 	if n > 0 {
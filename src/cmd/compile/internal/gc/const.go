@@ -503,6 +503,10 @@ func saveorig(n *Node) *Node {
 	return n.Orig
 }
 
+// largeConstString is the length in bytes above which -d hint
+// reports constant string concatenations.
+const largeConstString = 1 << 20
+
 // if n is constant, rewrite as OLITERAL node.
 func evconst(n *Node) {
 	// pick off just the opcodes that can be
@@ -553,14 +557,20 @@ func evconst(n *Node) {
 				// merge from i1 up to but not including i2
 				var strs []string
 				i2 := i1
+				large := false // an operand was already reported
 				for i2 < len(s) && Isconst(s[i2], CTSTR) {
 					strs = append(strs, s[i2].Val().U.(string))
+					large = large || len(strs[len(strs)-1]) > largeConstString
 					i2++
 				}
 
+				str := strings.Join(strs, "")
+				if len(str) > largeConstString && !large && hinting() {
+					Warnl(n.Lineno, "constant string literal of %d bytes", len(str))
+				}
 				nl := *s[i1]
 				nl.Orig = &nl
-				nl.SetVal(Val{strings.Join(strs, "")})
+				nl.SetVal(Val{str})
 				s[i1] = &nl
 				s = append(s[:i1+1], s[i2:]...)
 			}
@@ -1240,6 +1250,27 @@ func idealkind(n *Node) Ctype {
 	}
 }
 
+// DefaultType returns the type that defaultlit gives an untyped
+// constant of kind ct when no other type is implied, or nil if
+// ct has no default type (CTNIL and CTxxx).
+func DefaultType(ct Ctype) *Type {
+	switch ct {
+	case CTBOOL:
+		return Types[TBOOL]
+	case CTINT:
+		return Types[TINT]
+	case CTRUNE:
+		return runetype
+	case CTFLT:
+		return Types[TFLOAT64]
+	case CTCPLX:
+		return Types[TCOMPLEX128]
+	case CTSTR:
+		return Types[TSTRING]
+	}
+	return nil
+}
+
 // The result of defaultlit MUST be assigned back to n, e.g.
 // 	n.Left = defaultlit(n.Left, t)
 func defaultlit(n *Node, t *Type) *Node {
@@ -0,0 +1,67 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gc
+
+import "testing"
+
+func TestDefaultType(t *testing.T) {
+	savedTypes, savedRune := Types, runetype
+	defer func() { Types, runetype = savedTypes, savedRune }()
+	for _, et := range []EType{TBOOL, TINT, TINT32, TFLOAT64, TCOMPLEX128, TSTRING} {
+		if Types[et] == nil {
+			Types[et] = typ(et)
+		}
+	}
+	if runetype == nil {
+		runetype = Types[TINT32]
+	}
+
+	tests := []struct {
+		ct   Ctype
+		want *Type
+	}{
+		{CTxxx, nil},
+		{CTINT, Types[TINT]},
+		{CTRUNE, runetype},
+		{CTFLT, Types[TFLOAT64]},
+		{CTCPLX, Types[TCOMPLEX128]},
+		{CTSTR, Types[TSTRING]},
+		{CTBOOL, Types[TBOOL]},
+		{CTNIL, nil},
+	}
+	for _, tt := range tests {
+		if got := DefaultType(tt.ct); got != tt.want {
+			t.Errorf("DefaultType(%d) = %v, want %v", tt.ct, got, tt.want)
+		}
+	}
+}
+
+func TestEvconstAddStr(t *testing.T) {
+	lit := func(s string) *Node { return nodlit(Val{s}) }
+	x := Nod(ONAME, nil, nil)
+
+	// "a" + "b" + x + "c" + "d" + "e"
+	n := Nod(OADDSTR, nil, nil)
+	n.List.Set([]*Node{lit("a"), lit("b"), x, lit("c"), lit("d"), lit("e")})
+	evconst(n)
+	if n.Op != OADDSTR {
+		t.Fatalf("op = %v, want ADDSTR", Oconv(n.Op, 0))
+	}
+	l := n.List.Slice()
+	if len(l) != 3 {
+		t.Fatalf("%d operands after folding, want 3", len(l))
+	}
+	if !Isconst(l[0], CTSTR) || l[0].Val().U.(string) != "ab" || l[1] != x || !Isconst(l[2], CTSTR) || l[2].Val().U.(string) != "cde" {
+		t.Errorf("operands after folding = %v, want \"ab\", x, \"cde\"", l)
+	}
+
+	// "a" + "b" + "c"
+	n = Nod(OADDSTR, nil, nil)
+	n.List.Set([]*Node{lit("a"), lit("b"), lit("c")})
+	evconst(n)
+	if !Isconst(n, CTSTR) || n.Val().U.(string) != "abc" {
+		t.Errorf("folded %v, want \"abc\"", n)
+	}
+}
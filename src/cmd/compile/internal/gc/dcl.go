@@ -908,7 +908,7 @@ func interfacefield(n *Node) *Field {
 					break
 
 				case TFORW:
-					Yyerror("interface type loop involving %v", n.Type)
+					Yyerror("invalid recursive interface %v", n.Type)
 					f.Broke = true
 
 				default:
@@ -29,6 +29,7 @@ const (
 	FmtComma            // ","
 	FmtByte             // "hh"
 	FmtBody             // for printing export bodies
+	FmtTrunc            // elide long struct and interface bodies
 )
 
 //
@@ -83,6 +84,12 @@ var fmtpkgpfx int // %uT stickyness
 
 var fmtbody bool
 
+var fmttrunc int // FmtTrunc stickyness
+
+// fmtTruncFields is the number of fields or methods printed
+// in a struct or interface body formatted with FmtTrunc.
+const fmtTruncFields = 8
+
 //
 // E.g. for %S:	%+S %#S %-S	print an identifier properly qualified for debug/export/internal mode.
 //
@@ -615,6 +622,10 @@ func typefmt(t *Type, flag FmtFlag) string {
 		var buf bytes.Buffer
 		buf.WriteString("interface {")
 		for i, f := range t.Fields().Slice() {
+			if flag&FmtTrunc != 0 && i == fmtTruncFields {
+				fmt.Fprintf(&buf, "; ... +%d more", t.NumFields()-i)
+				break
+			}
 			if i != 0 {
 				buf.WriteString(";")
 			}
@@ -705,6 +716,10 @@ func typefmt(t *Type, flag FmtFlag) string {
 		} else {
 			buf.WriteString("struct {")
 			for i, f := range t.Fields().Slice() {
+				if flag&FmtTrunc != 0 && i == fmtTruncFields {
+					fmt.Fprintf(&buf, "; ... +%d more", t.NumFields()-i)
+					break
+				}
 				if i != 0 {
 					buf.WriteString(";")
 				}
@@ -1704,9 +1719,18 @@ func Tconv(t *Type, flag FmtFlag) string {
 	if fmtpkgpfx != 0 {
 		flag |= FmtUnsigned
 	}
+	if sf&FmtTrunc != 0 {
+		fmttrunc++
+	}
+	if fmttrunc != 0 {
+		flag |= FmtTrunc
+	}
 
 	str := typefmt(t, flag)
 
+	if sf&FmtTrunc != 0 {
+		fmttrunc--
+	}
 	if fmtmode == FTypeId && (sf&FmtUnsigned != 0) {
 		fmtpkgpfx--
 	}
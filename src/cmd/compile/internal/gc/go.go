@@ -229,6 +229,8 @@ var nsyntaxerrors int
 
 var decldepth int32
 
+var loopbodydepth int32 // number of enclosing for and range bodies being typechecked
+
 var safemode int
 
 var nolocalimports int
@@ -31,10 +31,13 @@ var (
 )
 
 var (
-	Debug_append int
-	Debug_panic  int
-	Debug_slice  int
-	Debug_wb     int
+	Debug_append  int
+	Debug_argsize int
+	Debug_complit int
+	Debug_hint    int
+	Debug_panic   int
+	Debug_slice   int
+	Debug_wb      int
 )
 
 // Debug arguments.
@@ -46,8 +49,11 @@ var debugtab = []struct {
 	val  *int
 }{
 	{"append", &Debug_append},         // print information about append compilation
+	{"argsize", &Debug_argsize},       // warn about by-value arguments larger than this many bytes
+	{"complit", &Debug_complit},       // report all errors in a composite literal
 	{"disablenil", &Disable_checknil}, // disable nil checks
 	{"gcprog", &Debug_gcprog},         // print dump of GC programs
+	{"hint", &Debug_hint},             // report hints about suspicious or costly code
 	{"nil", &Debug_checknil},          // print information about nil checks
 	{"panic", &Debug_panic},           // do not hide any compiler panic
 	{"slice", &Debug_slice},           // print information about slice compilation
@@ -488,6 +494,11 @@ func Main() {
 		}
 	}
 
+	// Report hints that need settled type widths.
+	if Debug_hint != 0 && nerrors+nsavederrors == 0 {
+		checkmapvalues()
+	}
+
 	if nerrors+nsavederrors != 0 {
 		errorexit()
 	}
@@ -1231,6 +1231,16 @@ func (p *parser) pseudocall() *Node {
 	case OCALL:
 		return x
 	case OPAREN:
+		// The parentheses never reach checkdefergo, so report
+		// a parenthesized non-call here.
+		y := x.Left
+		for y.Op == OPAREN {
+			y = y.Left
+		}
+		if y.Op != OCALL {
+			Yyerror("expression in go/defer must be function call, not parenthesized expression")
+			break
+		}
 		Yyerror("expression in go/defer must not be parenthesized")
 		// already progressed, no need to advance
 	default:
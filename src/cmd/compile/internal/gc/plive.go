@@ -908,7 +908,7 @@ func onebitwalktype1(t *Type, xoffset *int64, bv Bvec) {
 
 	case TINTER:
 		// struct { Itab *tab;	void *data; }
-		// or, when isnilinter(t)==true:
+		// or, when t.IsEmptyInterface():
 		// struct { Type *type; void *data; }
 		if *xoffset&int64(Widthptr-1) != 0 {
 			Fatalf("onebitwalktype1: invalid alignment, %v", t)
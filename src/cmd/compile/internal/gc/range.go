@@ -127,7 +127,9 @@ out:
 	}
 
 	decldepth++
+	loopbodydepth++
 	typecheckslice(n.Nbody.Slice(), Etop)
+	loopbodydepth--
 	decldepth--
 }
 
@@ -688,7 +688,7 @@ func slicelit(ctxt int, n *Node, var_ *Node, init *Nodes) {
 	// make an array type
 	t := n.Type.Copy()
 	t.Bound = n.Right.Val().U.(*Mpint).Int64()
-	t.Width = 0
+	t.Width = BADWIDTH
 	t.Sym = nil
 	t.Haspointers = 0
 	dowidth(t)
@@ -71,6 +71,42 @@ func Flusherrors() {
 	errors = errors[:0]
 }
 
+// A Diagnostic is a machine-readable record of an error or warning.
+type Diagnostic struct {
+	Lineno   int32 // position, as understood by linestr
+	Severity Severity
+	Category string // lower-case name of the Op being typechecked, if any
+	Msg      string // message, without position
+}
+
+// Severity classifies a Diagnostic.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+// A DiagnosticSink receives a Diagnostic for each error and warning.
+type DiagnosticSink interface {
+	Diagnose(d Diagnostic)
+}
+
+// Diagsink, if non-nil, is sent every diagnostic as it is reported,
+// in addition to the usual text output.
+var Diagsink DiagnosticSink
+
+func diagnose(line int32, sev Severity, format string, args ...interface{}) {
+	if Diagsink == nil {
+		return
+	}
+	d := Diagnostic{Lineno: line, Severity: sev, Msg: fmt.Sprintf(format, args...)}
+	if len(typecheck_tcstack) > 0 {
+		d.Category = strings.ToLower(opnames[typecheck_tcstack[len(typecheck_tcstack)-1].Op])
+	}
+	Diagsink.Diagnose(d)
+}
+
 func hcrash() {
 	if Debug['h'] != 0 {
 		Flusherrors()
@@ -88,6 +124,7 @@ func linestr(line int32) string {
 
 func yyerrorl(line int32, format string, args ...interface{}) {
 	adderr(line, format, args...)
+	diagnose(line, SeverityError, format, args...)
 
 	hcrash()
 	nerrors++
@@ -116,6 +153,7 @@ func Yyerror(format string, args ...interface{}) {
 	}
 
 	adderr(lineno, "%s", msg)
+	diagnose(lineno, SeverityError, "%s", msg)
 
 	hcrash()
 	nerrors++
@@ -128,17 +166,32 @@ func Yyerror(format string, args ...interface{}) {
 
 func Warn(fmt_ string, args ...interface{}) {
 	adderr(lineno, fmt_, args...)
+	diagnose(lineno, SeverityWarning, fmt_, args...)
 
 	hcrash()
 }
 
 func Warnl(line int32, fmt_ string, args ...interface{}) {
 	adderr(line, fmt_, args...)
+	diagnose(line, SeverityWarning, fmt_, args...)
 	if Debug['m'] != 0 {
 		Flusherrors()
 	}
 }
 
+// hinting reports whether -d hint diagnostics should be reported
+// for the code being typechecked.
+func hinting() bool {
+	return Debug_hint != 0 && usercode()
+}
+
+// usercode reports whether the code being typechecked comes from
+// the package being compiled. Imported declarations and inlined
+// function bodies (where Curfn is an ONAME) do not.
+func usercode() bool {
+	return importpkg == nil && incannedimport == 0 && (Curfn == nil || Curfn.Op == ODCLFUNC)
+}
+
 func Fatalf(fmt_ string, args ...interface{}) {
 	Flusherrors()
 
@@ -367,6 +420,7 @@ func saveorignode(n *Node) {
 }
 
 func maptype(key *Type, val *Type) *Type {
+	broke := false
 	if key != nil {
 		var bad *Type
 		atype := algtype1(key, &bad)
@@ -380,6 +434,7 @@ func maptype(key *Type, val *Type) *Type {
 		default:
 			if atype == ANOEQ {
 				Yyerror("invalid map key type %v", key)
+				broke = true
 			}
 
 			// will be resolved later.
@@ -402,6 +457,7 @@ func maptype(key *Type, val *Type) *Type {
 	t := typ(TMAP)
 	t.Down = key
 	t.Type = val
+	t.Broke = broke
 	return t
 }
 
@@ -605,10 +661,6 @@ func Isinter(t *Type) bool {
 	return t != nil && t.Etype == TINTER
 }
 
-func isnilinter(t *Type) bool {
-	return Isinter(t) && t.NumFields() == 0
-}
-
 func isideal(t *Type) bool {
 	if t == nil {
 		return false
@@ -690,7 +742,51 @@ func eqnote(a, b *string) bool {
 // pointer (t1 == t2), so there's no chance of chasing cycles
 // ad infinitum, so no need for a depth counter.
 func Eqtype(t1, t2 *Type) bool {
-	return eqtype1(t1, t2, nil)
+	return eqtypeCached(t1, t2, true)
+}
+
+// eqtypeIgnoreTags is like Eqtype but ignores struct field tags.
+func eqtypeIgnoreTags(t1, t2 *Type) bool {
+	return eqtypeCached(t1, t2, false)
+}
+
+type eqtypeKey struct {
+	t1, t2  *Type
+	cmpTags bool
+}
+
+// eqtypeCache memoizes comparisons of distinct unnamed struct,
+// interface, and func types, which require walking both types.
+// It is discarded when it reaches maxEqtypeCache entries.
+var eqtypeCache map[eqtypeKey]bool
+
+const maxEqtypeCache = 1 << 14
+
+func eqtypeCached(t1, t2 *Type, cmpTags bool) bool {
+	if t1 == t2 || t1 == nil || t2 == nil || t1.Etype != t2.Etype || t1.Sym != nil || t2.Sym != nil {
+		return eqtype1(t1, t2, cmpTags, nil)
+	}
+	switch t1.Etype {
+	case TSTRUCT, TINTER, TFUNC:
+	default:
+		return eqtype1(t1, t2, cmpTags, nil)
+	}
+	// Types still being built may change; only cache those
+	// whose widths, and so whose components, are settled.
+	if !widthSettled(t1) || !widthSettled(t2) {
+		return eqtype1(t1, t2, cmpTags, nil)
+	}
+
+	k := eqtypeKey{t1, t2, cmpTags}
+	if eq, ok := eqtypeCache[k]; ok {
+		return eq
+	}
+	eq := eqtype1(t1, t2, cmpTags, nil)
+	if eqtypeCache == nil || len(eqtypeCache) >= maxEqtypeCache {
+		eqtypeCache = make(map[eqtypeKey]bool)
+	}
+	eqtypeCache[k] = eq
+	return eq
 }
 
 type typePair struct {
@@ -698,7 +794,7 @@ type typePair struct {
 	t2 *Type
 }
 
-func eqtype1(t1, t2 *Type, assumedEqual map[typePair]struct{}) bool {
+func eqtype1(t1, t2 *Type, cmpTags bool, assumedEqual map[typePair]struct{}) bool {
 	if t1 == t2 {
 		return true
 	}
@@ -730,7 +826,7 @@ func eqtype1(t1, t2 *Type, assumedEqual map[typePair]struct{}) bool {
 		t1, i1 := IterFields(t1)
 		t2, i2 := IterFields(t2)
 		for ; t1 != nil && t2 != nil; t1, t2 = i1.Next(), i2.Next() {
-			if t1.Sym != t2.Sym || t1.Embedded != t2.Embedded || !eqtype1(t1.Type, t2.Type, assumedEqual) || !eqnote(t1.Note, t2.Note) {
+			if t1.Sym != t2.Sym || t1.Embedded != t2.Embedded || !eqtype1(t1.Type, t2.Type, cmpTags, assumedEqual) || (cmpTags && !eqnote(t1.Note, t2.Note)) {
 				return false
 			}
 		}
@@ -749,7 +845,7 @@ func eqtype1(t1, t2 *Type, assumedEqual map[typePair]struct{}) bool {
 			ta, ia := IterFields(f(t1))
 			tb, ib := IterFields(f(t2))
 			for ; ta != nil && tb != nil; ta, tb = ia.Next(), ib.Next() {
-				if ta.Isddd != tb.Isddd || !eqtype1(ta.Type, tb.Type, assumedEqual) {
+				if ta.Isddd != tb.Isddd || !eqtype1(ta.Type, tb.Type, cmpTags, assumedEqual) {
 					return false
 				}
 			}
@@ -770,13 +866,13 @@ func eqtype1(t1, t2 *Type, assumedEqual map[typePair]struct{}) bool {
 		}
 
 	case TMAP:
-		if !eqtype1(t1.Key(), t2.Key(), assumedEqual) {
+		if !eqtype1(t1.Key(), t2.Key(), cmpTags, assumedEqual) {
 			return false
 		}
-		return eqtype1(t1.Val(), t2.Val(), assumedEqual)
+		return eqtype1(t1.Val(), t2.Val(), cmpTags, assumedEqual)
 	}
 
-	return eqtype1(t1.Type, t2.Type, assumedEqual)
+	return eqtype1(t1.Type, t2.Type, cmpTags, assumedEqual)
 }
 
 // Are t1 and t2 equal struct types when field names are ignored?
@@ -833,7 +929,7 @@ func assignop(src *Type, dst *Type, why *string) Op {
 	// both are empty interface types.
 	// For assignable but different non-empty interface types,
 	// we want to recompute the itab.
-	if Eqtype(src.Orig, dst.Orig) && (src.Sym == nil || dst.Sym == nil || isnilinter(src)) {
+	if Eqtype(src.Orig, dst.Orig) && (src.Sym == nil || dst.Sym == nil || src.IsEmptyInterface()) {
 		return OCONVNOP
 	}
 
@@ -861,6 +957,12 @@ func assignop(src *Type, dst *Type, why *string) Op {
 				*why = fmt.Sprintf(":\n\t%v does not implement %v (%v method has pointer receiver)", src, dst, missing.Sym)
 			} else if have != nil {
 				*why = fmt.Sprintf(":\n\t%v does not implement %v (missing %v method)\n"+"\t\thave %v%v\n\t\twant %v%v", src, dst, missing.Sym, have.Sym, Tconv(have.Type, FmtShort|FmtByte), missing.Sym, Tconv(missing.Type, FmtShort|FmtByte))
+			} else if all := MissingMethods(src, dst); len(all) > 1 {
+				names := make([]string, len(all))
+				for i, m := range all {
+					names[i] = m.Sym.Name
+				}
+				*why = fmt.Sprintf(":\n\t%v does not implement %v (missing methods: %s)", src, dst, strings.Join(names, ", "))
 			} else {
 				*why = fmt.Sprintf(":\n\t%v does not implement %v (missing %v method)", src, dst, missing.Sym)
 			}
@@ -920,9 +1022,41 @@ func assignop(src *Type, dst *Type, why *string) Op {
 		return OCONVNOP
 	}
 
+	if why != nil {
+		if reorderedfields(src, dst) {
+			*why = fmt.Sprintf(":\n\tstructs %v and %v have the same fields in different order", src, dst)
+		} else if src.Etype == TCHAN && dst.Etype == TCHAN && dst.Chan&^src.Chan != 0 && Eqtype(src.Type, dst.Type) {
+			*why = fmt.Sprintf(":\n\tcannot assign %v to %v (channel direction mismatch)", src, dst)
+		}
+	}
+
 	return 0
 }
 
+// reorderedfields reports whether t1 and t2 are struct types
+// with the same field names and types, but in a different order.
+func reorderedfields(t1, t2 *Type) bool {
+	if t1 == nil || t2 == nil || t1.Etype != TSTRUCT || t2.Etype != TSTRUCT || t1.NumFields() != t2.NumFields() {
+		return false
+	}
+	reordered := false
+	used := make([]bool, t2.NumFields())
+outer:
+	for i, f := range t1.Fields().Slice() {
+		for j, g := range t2.Fields().Slice() {
+			if !used[j] && f.Sym == g.Sym && Eqtype(f.Type, g.Type) {
+				used[j] = true
+				if i != j {
+					reordered = true
+				}
+				continue outer
+			}
+		}
+		return false
+	}
+	return reordered
+}
+
 // Can we convert a value of type src to a value of type dst?
 // If so, return op code to use in conversion (maybe OCONVNOP).
 // If not, return 0.
@@ -1020,6 +1154,20 @@ func convertop(src *Type, dst *Type, why *string) Op {
 		return OCONVNOP
 	}
 
+	if why != nil && Isfixedarray(src) && Isslice(dst) && Eqtype(src.Type, dst.Type) {
+		*why = " (to get a slice of an array, use arr[:])"
+	}
+
+	if why != nil {
+		s, d := src, dst
+		if Isptr[s.Etype] && Isptr[d.Etype] && s.Sym == nil && d.Sym == nil {
+			s, d = s.Type, d.Type
+		}
+		if s.Orig.Etype == TSTRUCT && eqtypeIgnoreTags(s.Orig, d.Orig) {
+			*why = " (struct types differ only in tags)"
+		}
+	}
+
 	return 0
 }
 
@@ -2129,6 +2277,41 @@ func implements(t, iface *Type, m, samename **Field, ptr *int) bool {
 	return true
 }
 
+// MissingMethods returns the methods of interface type iface
+// that t does not have at all, in the order iface declares them.
+// Methods that t has with the wrong type or only on the pointer
+// receiver are not included.
+func MissingMethods(t, iface *Type) []*Field {
+	var missing []*Field
+	if t.Etype == TINTER {
+	Outer:
+		for _, im := range iface.Fields().Slice() {
+			for _, tm := range t.Fields().Slice() {
+				if tm.Sym == im.Sym {
+					continue Outer
+				}
+			}
+			missing = append(missing, im)
+		}
+		return missing
+	}
+
+	t = methtype(t, 0)
+	if t != nil {
+		expandmeth(t)
+	}
+	for _, im := range iface.Fields().Slice() {
+		if im.Broke {
+			continue
+		}
+		var followptr bool
+		if ifacelookdot(im.Sym, t, &followptr, false) == nil {
+			missing = append(missing, im)
+		}
+	}
+	return missing
+}
+
 // even simpler simtype; get rid of ptr, bool.
 // assuming that the front end has rejected
 // all the invalid conversions (like ptr -> bool)
@@ -2395,7 +2578,7 @@ func isdirectiface(t *Type) bool {
 // 'I' if t is an interface type, and 'E' if t is an empty interface type.
 // It is used to build calls to the conv* and assert* runtime routines.
 func (t *Type) iet() byte {
-	if isnilinter(t) {
+	if t.IsEmptyInterface() {
 		return 'E'
 	}
 	if Isinter(t) {
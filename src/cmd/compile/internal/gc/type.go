@@ -182,35 +182,58 @@ func (f *Field) End() int64 {
 	return f.Offset + f.Type.Width
 }
 
+// Align returns the alignment in bytes of f's type.
+func (f *Field) Align() int64 {
+	return f.Type.Alignment()
+}
+
 // Fields is a pointer to a slice of *Field.
 // This saves space in Types that do not have fields or methods
 // compared to a simple slice of *Field.
 type Fields struct {
-	s *[]*Field
+	s *fieldList
+}
+
+// fieldList holds the entries of a Fields and, once the list
+// is long enough to be worth it, an index of them by symbol.
+type fieldList struct {
+	slice []*Field
+	index map[*Sym]fieldIndex // built lazily by lookup
 }
 
+type fieldIndex struct {
+	i   int  // position of the first field with the symbol
+	dup bool // whether another field has the same symbol
+}
+
+// fieldIndexMin is the number of fields at which lookup
+// switches from a linear scan to an index.
+const fieldIndexMin = 32
+
 // Len returns the number of entries in f.
 func (f *Fields) Len() int {
 	if f.s == nil {
 		return 0
 	}
-	return len(*f.s)
+	return len(f.s.slice)
 }
 
 // Slice returns the entries in f as a slice.
 // Changes to the slice entries will be reflected in f.
+// The symbols of the entries must not be changed
+// once f has been searched with Lookup.
 func (f *Fields) Slice() []*Field {
 	if f.s == nil {
 		return nil
 	}
-	return *f.s
+	return f.s.slice
 }
 
 // Set sets f to a slice.
 // This takes ownership of the slice.
 func (f *Fields) Set(s []*Field) {
 	if len(s) != 0 {
-		f.s = &s
+		f.s = &fieldList{slice: s}
 	} else {
 		f.s = nil
 	}
@@ -219,9 +242,60 @@ func (f *Fields) Set(s []*Field) {
 // Append appends entries to f.
 func (f *Fields) Append(s ...*Field) {
 	if f.s == nil {
-		f.s = new([]*Field)
+		f.s = new(fieldList)
 	}
-	*f.s = append(*f.s, s...)
+	f.s.slice = append(f.s.slice, s...)
+	f.s.index = nil
+}
+
+// Lookup returns the first entry in f with symbol s,
+// or nil if there is none.
+func (f *Fields) Lookup(s *Sym) *Field {
+	r, _ := f.lookup(s)
+	return r
+}
+
+// lookup is like Lookup but also reports whether
+// more than one entry in f has symbol s.
+func (f *Fields) lookup(s *Sym) (r *Field, dup bool) {
+	if f.s == nil {
+		return nil, false
+	}
+	l := f.s
+	if len(l.slice) < fieldIndexMin {
+		for _, f := range l.slice {
+			if f.Sym != s {
+				continue
+			}
+			if r != nil {
+				return r, true
+			}
+			r = f
+		}
+		return r, false
+	}
+	if l.index == nil {
+		l.index = make(map[*Sym]fieldIndex, len(l.slice))
+		for i, f := range l.slice {
+			if x, ok := l.index[f.Sym]; ok {
+				x.dup = true
+				l.index[f.Sym] = x
+				continue
+			}
+			l.index[f.Sym] = fieldIndex{i: i}
+		}
+	}
+	x, ok := l.index[s]
+	if !ok {
+		return nil, false
+	}
+	if l.slice[x.i].Sym != s {
+		// The entry was replaced through Slice since the
+		// index was built. Rebuild it rather than trust it.
+		l.index = nil
+		return f.lookup(s)
+	}
+	return l.slice[x.i], x.dup
 }
 
 // typ returns a new Type of the specified kind.
@@ -348,6 +422,31 @@ func (t *Type) Val() *Type {
 	return t.Type
 }
 
+// ChanDir is the direction of a channel type.
+type ChanDir uint8
+
+const (
+	RecvOnly ChanDir = Crecv
+	SendOnly ChanDir = Csend
+	BothDir  ChanDir = Cboth
+)
+
+// ChanDir returns the direction of channel type t.
+func (t *Type) ChanDir() ChanDir {
+	t.wantEtype(TCHAN)
+	return ChanDir(t.Chan)
+}
+
+// IsRecvOnly reports whether t is a receive-only channel type.
+func (t *Type) IsRecvOnly() bool {
+	return t.ChanDir() == RecvOnly
+}
+
+// IsSendOnly reports whether t is a send-only channel type.
+func (t *Type) IsSendOnly() bool {
+	return t.ChanDir() == SendOnly
+}
+
 func (t *Type) Methods() *Fields {
 	// TODO(mdempsky): Validate t?
 	return &t.methods
@@ -365,6 +464,37 @@ func (t *Type) Fields() *Fields {
 	return &t.fields
 }
 
+// InterfaceMethods returns the method fields of interface type t.
+// It returns nil if t is not an interface type.
+func (t *Type) InterfaceMethods() []*Field {
+	if t == nil || t.Etype != TINTER {
+		return nil
+	}
+	return t.Fields().Slice()
+}
+
+// A FieldTag pairs a struct field with its tag string.
+type FieldTag struct {
+	Field *Field
+	Tag   string // empty if the field has no tag
+}
+
+// FieldsWithTags returns the fields of struct type t, each paired
+// with its tag string. It returns nil if t is not a struct type.
+func (t *Type) FieldsWithTags() []FieldTag {
+	if t.Etype != TSTRUCT {
+		return nil
+	}
+	fields := make([]FieldTag, t.NumFields())
+	for i, f := range t.Fields().Slice() {
+		fields[i].Field = f
+		if f.Note != nil {
+			fields[i].Tag = *f.Note
+		}
+	}
+	return fields
+}
+
 // Field returns the i'th field/method of struct/interface type t.
 func (t *Type) Field(i int) *Field {
 	return t.Fields().Slice()[i]
@@ -405,15 +535,257 @@ func (t *Type) Alignment() int64 {
 	return int64(t.Align)
 }
 
+// Walk calls visit for t and every type reachable from it:
+// element, key, and value types, field and method types, and
+// receiver, parameter, and result types. Each type is visited
+// at most once, so recursive types are handled. If visit returns
+// false, the types reachable from its argument are not walked.
+func (t *Type) Walk(visit func(*Type) bool) {
+	t.walk(visit, make(map[*Type]bool))
+}
+
+func (t *Type) walk(visit func(*Type) bool, seen map[*Type]bool) {
+	if t == nil || seen[t] {
+		return
+	}
+	seen[t] = true
+	if !visit(t) {
+		return
+	}
+	switch t.Etype {
+	case TPTR32, TPTR64, TARRAY, TCHAN:
+		t.Type.walk(visit, seen)
+
+	case TMAP:
+		t.Key().walk(visit, seen)
+		t.Val().walk(visit, seen)
+
+	case TSTRUCT, TINTER:
+		for _, f := range t.Fields().Slice() {
+			f.Type.walk(visit, seen)
+		}
+
+	case TFUNC:
+		for _, p := range recvsParamsResults {
+			for _, f := range p(t).Fields().Slice() {
+				f.Type.walk(visit, seen)
+			}
+		}
+	}
+}
+
+// SizeOf returns the size of t in bytes, like unsafe.Sizeof.
+func (t *Type) SizeOf() int64 {
+	return t.Size()
+}
+
+// AlignOf returns the alignment of t in bytes, like unsafe.Alignof.
+func (t *Type) AlignOf() int64 {
+	return t.Alignment()
+}
+
+// FieldOffsetOf returns the offset in bytes of the field sym
+// declared directly in struct type t, like unsafe.Offsetof.
+// It returns -1 if t is not a struct or has no such field.
+func (t *Type) FieldOffsetOf(sym *Sym) int64 {
+	if t.Etype != TSTRUCT {
+		return -1
+	}
+	dowidth(t)
+	for _, f := range t.Fields().Slice() {
+		if f.Sym == sym {
+			return f.Offset
+		}
+	}
+	return -1
+}
+
+// MaxFieldAlign returns the largest alignment of any field
+// declared directly in struct type t, or 0 if t has no fields.
+func (t *Type) MaxFieldAlign() int64 {
+	t.wantEtype(TSTRUCT)
+	var max int64
+	for _, f := range t.Fields().Slice() {
+		if a := f.Align(); a > max {
+			max = a
+		}
+	}
+	return max
+}
+
+// FieldPadding returns, for each field of struct type t, the number
+// of padding bytes inserted between it and the previous field, or the
+// start of the struct for the first field.
+func (t *Type) FieldPadding() []int64 {
+	t.wantEtype(TSTRUCT)
+	dowidth(t)
+	fields := t.Fields().Slice()
+	pad := make([]int64, len(fields))
+	var end int64
+	for i, f := range fields {
+		pad[i] = f.Offset - end
+		end = f.End()
+	}
+	return pad
+}
+
 func (t *Type) SimpleString() string {
 	return Econv(t.Etype)
 }
 
+// BaseName returns the name of t without its package qualifier,
+// or the kind of t, such as "struct" or "slice", if t is unnamed.
+func (t *Type) BaseName() string {
+	if t.Sym != nil {
+		return t.Sym.Name
+	}
+	return typekind(t)
+}
+
+// VerifyType checks the internal invariants of t and returns an
+// error describing the first one that does not hold. It is meant
+// for tests and debugging, once t's width has been settled.
+func VerifyType(t *Type) error {
+	if t.Orig == nil {
+		return fmt.Errorf("%v: nil Orig", t)
+	}
+	if t.Orig != t && t.Etype != TFORW && !t.Broke && t.Orig.Etype != t.Etype {
+		return fmt.Errorf("%v: Orig %v has kind %v, want %v", t, t.Orig, Econv(t.Orig.Etype), Econv(t.Etype))
+	}
+	if t.Width != BADWIDTH && t.Width < 0 {
+		return fmt.Errorf("%v: width %d is unsettled", t, t.Width)
+	}
+
+	if t.Etype == TSTRUCT && t.Width != BADWIDTH {
+		var end int64
+		for _, f := range t.Fields().Slice() {
+			if f.Offset == BADWIDTH {
+				return fmt.Errorf("%v: field %v has no offset", t, f.Sym)
+			}
+			if f.Offset < end {
+				return fmt.Errorf("%v: field %v at offset %d overlaps previous field ending at %d", t, f.Sym, f.Offset, end)
+			}
+			end = f.End()
+		}
+		if end > t.Width {
+			return fmt.Errorf("%v: fields end at %d, beyond width %d", t, end, t.Width)
+		}
+	}
+
+	if t.Etype == TMAP {
+		for _, x := range []*Type{t.Bucket, t.Hmap, t.Hiter} {
+			if x != nil && x.Map != t {
+				return fmt.Errorf("%v: internal type %v does not link back to map", t, x)
+			}
+		}
+	}
+	if m := t.Map; m != nil && m.Bucket != t && m.Hmap != t && m.Hiter != t {
+		return fmt.Errorf("%v: not an internal type of map %v", t, m)
+	}
+	return nil
+}
+
 func (t *Type) Equal(u ssa.Type) bool {
 	x, ok := u.(*Type)
 	return ok && Eqtype(t, x)
 }
 
+// Identical reports whether t and x are identical types,
+// as defined by the Go spec.
+func (t *Type) Identical(x *Type) bool {
+	return Eqtype(t, x)
+}
+
+// DeepEqual reports whether t and x are structurally identical.
+// Unlike Eqtype, which requires a named type to be the same *Type,
+// DeepEqual treats two named types as equal if they have the same
+// name and package and deeply equal underlying types. This makes it
+// suitable for comparing types that were constructed independently,
+// such as the same type imported twice. Cycles through named or
+// unnamed types terminate: a pair of types already under comparison
+// is assumed to be equal.
+func (t *Type) DeepEqual(x *Type) bool {
+	return deepEqual(t, x, make(map[typePair]bool))
+}
+
+func deepEqual(t, x *Type, visited map[typePair]bool) bool {
+	if t == x {
+		return true
+	}
+	if t == nil || x == nil || t.Etype != x.Etype {
+		return false
+	}
+	if t.Sym != nil || x.Sym != nil {
+		if Eqtype(t, x) {
+			// Same type, or byte/uint8 and rune/int32.
+			return true
+		}
+		if !sameSym(t.Sym, x.Sym) {
+			return false
+		}
+	}
+
+	p := typePair{t, x}
+	if visited[p] {
+		return true
+	}
+	visited[p] = true
+
+	switch t.Etype {
+	case TSTRUCT, TINTER:
+		t1, ti := IterFields(t)
+		x1, xi := IterFields(x)
+		for ; t1 != nil && x1 != nil; t1, x1 = ti.Next(), xi.Next() {
+			if !sameSym(t1.Sym, x1.Sym) || t1.Embedded != x1.Embedded || !eqnote(t1.Note, x1.Note) || !deepEqual(t1.Type, x1.Type, visited) {
+				return false
+			}
+		}
+		return t1 == nil && x1 == nil
+
+	case TFUNC:
+		for _, f := range paramsResults {
+			ta, ia := IterFields(f(t))
+			tb, ib := IterFields(f(x))
+			for ; ta != nil && tb != nil; ta, tb = ia.Next(), ib.Next() {
+				if ta.Isddd != tb.Isddd || !deepEqual(ta.Type, tb.Type, visited) {
+					return false
+				}
+			}
+			if ta != nil || tb != nil {
+				return false
+			}
+		}
+		return true
+
+	case TARRAY:
+		if t.Bound != x.Bound {
+			return false
+		}
+
+	case TCHAN:
+		if t.Chan != x.Chan {
+			return false
+		}
+
+	case TMAP:
+		return deepEqual(t.Key(), x.Key(), visited) && deepEqual(t.Val(), x.Val(), visited)
+	}
+
+	return deepEqual(t.Type, x.Type, visited)
+}
+
+// sameSym reports whether a and b name the same identifier,
+// even if they are distinct *Syms.
+func sameSym(a, b *Sym) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil || a.Name != b.Name {
+		return false
+	}
+	return a.Pkg == b.Pkg || a.Pkg != nil && b.Pkg != nil && a.Pkg.Path == b.Pkg.Path
+}
+
 // Compare compares types for purposes of the SSA back
 // end, returning an ssa.Cmp (one of CMPlt, CMPeq, CMPgt).
 // The answers are correct for an optimizer
@@ -535,8 +907,8 @@ func (t *Type) cmp(x *Type) ssa.Cmp {
 		}
 		return t.Val().cmp(x.Val())
 
-	case TPTR32, TPTR64:
-		// No special cases for these two, they are handled
+	case TPTR32, TPTR64, TDDDFIELD:
+		// No special cases for these, they are handled
 		// by the general code after the switch.
 
 	case TSTRUCT:
@@ -621,7 +993,7 @@ func (t *Type) cmp(x *Type) ssa.Cmp {
 		panic(e)
 	}
 
-	// Common element type comparison for TARRAY, TCHAN, TPTR32, and TPTR64.
+	// Common element type comparison for TARRAY, TCHAN, TPTR32, TPTR64, and TDDDFIELD.
 	return t.Type.cmp(x.Type)
 }
 
@@ -653,11 +1025,179 @@ func (t *Type) IsComplex() bool {
 	return t.Etype == TCOMPLEX64 || t.Etype == TCOMPLEX128
 }
 
+// ComplexElem returns the type of the real and imaginary parts
+// of complex type t, or nil if t is not a complex type.
+func (t *Type) ComplexElem() *Type {
+	if !t.IsComplex() {
+		return nil
+	}
+	return Types[cplxsubtype(t.Etype)]
+}
+
+// NumericRank returns the promotion rank of numeric type t:
+// integer types rank by size, then floating-point types by size,
+// then complex types by size. It returns 0 for non-numeric types.
+// Signed and unsigned integers of the same size have the same rank.
+func (t *Type) NumericRank() int {
+	switch t.Etype {
+	case TINT8, TUINT8:
+		return 1
+	case TINT16, TUINT16:
+		return 2
+	case TINT32, TUINT32:
+		return 3
+	case TINT64, TUINT64:
+		return 4
+	case TINT, TUINT:
+		if Widthint == 4 {
+			return 3
+		}
+		return 4
+	case TUINTPTR:
+		if Widthptr == 4 {
+			return 3
+		}
+		return 4
+	case TFLOAT32:
+		return 5
+	case TFLOAT64:
+		return 6
+	case TCOMPLEX64:
+		return 7
+	case TCOMPLEX128:
+		return 8
+	}
+	return 0
+}
+
 func (t *Type) IsPtr() bool {
 	return t.Etype == TPTR32 || t.Etype == TPTR64 || t.Etype == TUNSAFEPTR ||
 		t.Etype == TMAP || t.Etype == TCHAN || t.Etype == TFUNC
 }
 
+// ContainsPointer reports whether a value of type t contains pointers
+// that the garbage collector must scan, including those in strings,
+// slices, interfaces, maps, channels, and funcs. Unlike haspointers,
+// the result is not cached in t.
+func (t *Type) ContainsPointer() bool {
+	switch t.Etype {
+	case TSTRING, TPTR32, TPTR64, TUNSAFEPTR, TINTER, TCHAN, TMAP, TFUNC:
+		return true
+
+	case TARRAY:
+		if t.Bound < 0 { // slice
+			return true
+		}
+		return t.Bound > 0 && t.Type.ContainsPointer()
+
+	case TSTRUCT:
+		for _, f := range t.Fields().Slice() {
+			if f.Type.ContainsPointer() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FuncAssignableTo reports whether a value of func type t is
+// assignable to func type x. Func types must have identical
+// underlying types, so a variadic func(...T) is assignable to
+// neither func([]T) nor the reverse, even though the final
+// parameter has type []T in both.
+func (t *Type) FuncAssignableTo(x *Type) bool {
+	if t.Etype != TFUNC || x.Etype != TFUNC {
+		return false
+	}
+	return assignop(t, x, nil) != 0
+}
+
+// ReceiverBase returns the named base type of a method receiver
+// of type T or *T, or nil if t cannot be a method receiver.
+func (t *Type) ReceiverBase() *Type {
+	return methtype(t, 1)
+}
+
+// NumMethods returns the number of methods of t. For an interface
+// type these are its interface methods. For a named type T, or a
+// pointer *T to one, they are the methods declared with receiver T
+// or *T, not including promoted methods; a pointer is dereferenced
+// so that T and *T report the same list. NumMethods fails for other
+// types.
+func (t *Type) NumMethods() int {
+	return len(t.methodList())
+}
+
+// Method returns the i'th method of t, in the order described
+// by NumMethods.
+func (t *Type) Method(i int) *Field {
+	return t.methodList()[i]
+}
+
+func (t *Type) methodList() []*Field {
+	if t.Etype == TINTER {
+		return t.Fields().Slice()
+	}
+	base := methtype(t, 1)
+	if base == nil {
+		Fatalf("methodList: type %v is not an interface or named type", t)
+	}
+	return base.Methods().Slice()
+}
+
+// WithoutReceiver returns the func type of method type t with
+// its receiver removed. The parameters and results are copied,
+// so computing the new type's width does not disturb t's.
+func (t *Type) WithoutReceiver() *Type {
+	t.wantEtype(TFUNC)
+
+	copyfunargs := func(s *Type) *Type {
+		ns := typ(TSTRUCT)
+		ns.Funarg = true
+		var fields []*Field
+		for _, f := range s.Fields().Slice() {
+			fields = append(fields, f.Copy())
+		}
+		ns.SetFields(fields)
+		return ns
+	}
+
+	nt := typ(TFUNC)
+	*nt.RecvsP() = copyfunargs(typ(TSTRUCT)) // no receiver
+	*nt.ResultsP() = copyfunargs(t.Results())
+	*nt.ParamsP() = copyfunargs(t.Params())
+	nt.Outnamed = t.Outnamed
+	nt.Broke = t.Broke
+	return nt
+}
+
+// IsNamed reports whether t is a defined (named) type other than
+// a predeclared type such as int, error, or unsafe.Pointer.
+func (t *Type) IsNamed() bool {
+	if t.Sym == nil || t.Sym.Pkg == builtinpkg || t.Sym.Pkg == unsafepkg {
+		return false
+	}
+	if t == Types[t.Etype] {
+		return false
+	}
+	// A defined type is a copy of its underlying type literal (see
+	// copytype), so its Orig still refers to that literal. Only a
+	// type that has not been resolved yet is its own Orig.
+	return t.Orig != t || t.Etype == TFORW
+}
+
+// IsScalar reports whether t is a boolean, numeric, or pointer type.
+// Strings, interfaces, slices, structs, and arrays are not scalar,
+// and neither are maps, channels, and funcs, although IsPtr
+// reports them as pointer-shaped.
+func (t *Type) IsScalar() bool {
+	switch t.Etype {
+	case TPTR32, TPTR64, TUNSAFEPTR:
+		return true
+	}
+	return t.IsBoolean() || t.IsInteger() || t.IsFloat() || t.IsComplex()
+}
+
 func (t *Type) IsString() bool {
 	return t.Etype == TSTRING
 }
@@ -682,10 +1222,57 @@ func (t *Type) IsStruct() bool {
 	return t.Etype == TSTRUCT
 }
 
+// IsTuple reports whether t is the result list of a function
+// returning more than one value.
+func (t *Type) IsTuple() bool {
+	return t.Etype == TSTRUCT && t.Funarg && t.NumFields() > 1
+}
+
+// IsIncomplete reports whether t is not yet fully defined,
+// either because it is still a forward declaration or because
+// an earlier error left it broken.
+func (t *Type) IsIncomplete() bool {
+	return t.Etype == TFORW || t.Broke
+}
+
 func (t *Type) IsInterface() bool {
 	return t.Etype == TINTER
 }
 
+// Underlying returns the type literal or predeclared type underlying
+// t. For a named type declared as type T U, it is the underlying type
+// of U. For any other type it is t itself.
+func (t *Type) Underlying() *Type {
+	for t.Orig != nil && t.Orig != t {
+		t = t.Orig
+	}
+	return t
+}
+
+// IsNilable reports whether nil is a valid value of type t,
+// and so whether values of type t can be compared to nil.
+func (t *Type) IsNilable() bool {
+	switch t.Etype {
+	case TPTR32, TPTR64, TUNSAFEPTR, TCHAN, TFUNC, TINTER, TMAP:
+		return true
+	case TARRAY:
+		return t.Bound < 0
+	}
+	return false
+}
+
+// IsComparable reports whether == is defined on values of type t.
+// Slice, map, and func values can still be compared to nil.
+func (t *Type) IsComparable() bool {
+	return algtype1(t, nil) != ANOEQ
+}
+
+// IsEmptyInterface reports whether t is an interface type
+// with no methods, such as interface{}.
+func (t *Type) IsEmptyInterface() bool {
+	return t.IsInterface() && t.NumFields() == 0
+}
+
 func (t *Type) ElemType() ssa.Type {
 	switch t.Etype {
 	case TARRAY, TPTR32, TPTR64:
@@ -0,0 +1,1175 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gc
+
+import (
+	"cmd/compile/internal/ssa"
+	"fmt"
+	"testing"
+)
+
+func TestInterfaceMethods(t *testing.T) {
+	m1 := newField()
+	m1.Sym = &Sym{Name: "Read"}
+	m1.Type = typ(TFUNC)
+	m2 := newField()
+	m2.Sym = &Sym{Name: "Write"}
+	m2.Type = typ(TFUNC)
+
+	iface := typ(TINTER)
+	iface.SetFields([]*Field{m1, m2})
+
+	got := iface.InterfaceMethods()
+	if len(got) != 2 || got[0] != m1 || got[1] != m2 {
+		t.Errorf("InterfaceMethods() = %v, want [%v %v]", got, m1, m2)
+	}
+
+	for _, tt := range []*Type{typ(TSTRUCT), typ(TINT), nil} {
+		if got := tt.InterfaceMethods(); got != nil {
+			t.Errorf("%v.InterfaceMethods() = %v, want nil", tt, got)
+		}
+	}
+}
+
+func TestFieldsLookup(t *testing.T) {
+	for _, n := range []int{3, fieldIndexMin + 5} {
+		syms := make([]*Sym, n)
+		var fs Fields
+		for i := range syms {
+			syms[i] = &Sym{Name: "f"}
+			f := newField()
+			f.Sym = syms[i]
+			fs.Append(f)
+		}
+		for i, s := range syms {
+			if f, dup := fs.lookup(s); f != fs.Slice()[i] || dup {
+				t.Errorf("%d fields: lookup(%d) = %p, %v, want %p, false", n, i, f, dup, fs.Slice()[i])
+			}
+		}
+		if f := fs.Lookup(&Sym{Name: "f"}); f != nil {
+			t.Errorf("%d fields: Lookup(unknown) = %p, want nil", n, f)
+		}
+
+		// Appending after a lookup must be visible to the next one,
+		// including a symbol that is now ambiguous.
+		extra, dupf := newField(), newField()
+		extra.Sym = &Sym{Name: "extra"}
+		dupf.Sym = syms[1]
+		fs.Append(extra, dupf)
+		if f := fs.Lookup(extra.Sym); f != extra {
+			t.Errorf("%d fields: Lookup(extra) after Append = %p, want %p", n, f, extra)
+		}
+		if f, dup := fs.lookup(syms[1]); f != fs.Slice()[1] || !dup {
+			t.Errorf("%d fields: lookup(dup) = %p, %v, want %p, true", n, f, dup, fs.Slice()[1])
+		}
+
+		// Replacing an entry through Slice must not leave
+		// lookup returning a field with a different symbol.
+		renamed := newField()
+		renamed.Sym = &Sym{Name: "renamed"}
+		fs.Slice()[0] = renamed
+		if f, _ := fs.lookup(syms[0]); f != nil && f.Sym != syms[0] {
+			t.Errorf("%d fields: lookup after Slice write = %v, want a field with symbol %v or nil", n, f.Sym, syms[0])
+		}
+		if f := fs.Lookup(renamed.Sym); f != renamed {
+			t.Errorf("%d fields: Lookup(renamed) = %p, want %p", n, f, renamed)
+		}
+
+		// Set replaces the entries and so the index.
+		fs.Set([]*Field{extra})
+		if f := fs.Lookup(syms[0]); f != nil {
+			t.Errorf("%d fields: Lookup after Set = %p, want nil", n, f)
+		}
+		if f := fs.Lookup(extra.Sym); f != extra {
+			t.Errorf("%d fields: Lookup(extra) after Set = %p, want %p", n, f, extra)
+		}
+	}
+}
+
+func TestNumericRank(t *testing.T) {
+	order := []EType{TINT8, TINT16, TINT32, TINT64, TFLOAT32, TFLOAT64, TCOMPLEX64, TCOMPLEX128}
+	for i := 1; i < len(order); i++ {
+		lo, hi := typ(order[i-1]), typ(order[i])
+		if lo.NumericRank() >= hi.NumericRank() {
+			t.Errorf("rank of %v (%d) not less than rank of %v (%d)", order[i-1], lo.NumericRank(), order[i], hi.NumericRank())
+		}
+	}
+	if u, s := typ(TUINT8).NumericRank(), typ(TINT8).NumericRank(); u != s {
+		t.Errorf("uint8 rank %d != int8 rank %d", u, s)
+	}
+	for _, et := range []EType{TBOOL, TSTRING, TSTRUCT} {
+		if r := typ(et).NumericRank(); r != 0 {
+			t.Errorf("rank of %v = %d, want 0", et, r)
+		}
+	}
+}
+
+func TestIsScalar(t *testing.T) {
+	scalar := []EType{TBOOL, TINT8, TUINT64, TINT, TUINTPTR, TFLOAT64, TCOMPLEX128, TPTR32, TPTR64, TUNSAFEPTR}
+	for _, et := range scalar {
+		if !typ(et).IsScalar() {
+			t.Errorf("%v is not scalar", et)
+		}
+	}
+	nonscalar := []EType{TSTRING, TINTER, TSTRUCT, TARRAY, TMAP, TCHAN, TFUNC}
+	for _, et := range nonscalar {
+		if typ(et).IsScalar() {
+			t.Errorf("%v is scalar", et)
+		}
+	}
+}
+
+// structOf returns the struct type struct{a T0; b T1; ...}
+// with the given field types.
+func structOf(types ...*Type) *Type {
+	var fields []*Field
+	for i, ft := range types {
+		f := newField()
+		f.Sym = &Sym{Name: string(rune('a' + i))}
+		f.Type = ft
+		fields = append(fields, f)
+	}
+	t := typ(TSTRUCT)
+	t.SetFields(fields)
+	return t
+}
+
+func TestContainsPointer(t *testing.T) {
+	ptr := typ(TPTR64)
+	ptr.Type = typ(TINT)
+
+	array := func(n int64, elem *Type) *Type {
+		t := typ(TARRAY)
+		t.Bound = n
+		t.Type = elem
+		return t
+	}
+
+	tests := []struct {
+		name string
+		t    *Type
+		want bool
+	}{
+		{"[4]*int", array(4, ptr), true},
+		{"struct{int}", structOf(typ(TINT)), false},
+		{"struct{int; *int}", structOf(typ(TINT), ptr), true},
+		{"string", typ(TSTRING), true},
+		{"[0]*int", array(0, ptr), false},
+		{"[]int", array(-1, typ(TINT)), true},
+		{"[2][3]int", array(2, array(3, typ(TINT))), false},
+		{"interface{}", typ(TINTER), true},
+		{"map", typ(TMAP), true},
+		{"float64", typ(TFLOAT64), false},
+	}
+	for _, tt := range tests {
+		if got := tt.t.ContainsPointer(); got != tt.want {
+			t.Errorf("%s: ContainsPointer() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// setWidths configures the target word sizes needed by dowidth
+// and returns a function that restores the previous values.
+func setWidths() (restore func()) {
+	ptr, reg, maxwidth := Widthptr, Widthreg, Thearch.MAXWIDTH
+	Widthptr, Widthreg, Thearch.MAXWIDTH = 8, 8, 1<<50
+	return func() {
+		Widthptr, Widthreg, Thearch.MAXWIDTH = ptr, reg, maxwidth
+	}
+}
+
+// setPtrs marks TPTR64 as a pointer type in Isptr, as typeinit would,
+// and returns a function that restores its previous setting.
+func setPtrs() (restore func()) {
+	ptr := Isptr[TPTR64]
+	Isptr[TPTR64] = true
+	return func() {
+		Isptr[TPTR64] = ptr
+	}
+}
+
+func TestSizeAlignOffsetOf(t *testing.T) {
+	defer setWidths()()
+
+	a, b, c, d := &Sym{Name: "a"}, &Sym{Name: "b"}, &Sym{Name: "c"}, &Sym{Name: "d"}
+	var fields []*Field
+	for _, x := range []struct {
+		sym *Sym
+		et  EType
+	}{{a, TINT8}, {b, TINT64}, {c, TINT32}} {
+		f := newField()
+		f.Sym = x.sym
+		f.Type = typ(x.et)
+		fields = append(fields, f)
+	}
+	s := typ(TSTRUCT)
+	s.SetFields(fields)
+
+	// struct { a int8; b int64; c int32 }
+	if got := s.SizeOf(); got != 24 {
+		t.Errorf("SizeOf() = %d, want 24", got)
+	}
+	if got := s.AlignOf(); got != 8 {
+		t.Errorf("AlignOf() = %d, want 8", got)
+	}
+	for _, tt := range []struct {
+		sym  *Sym
+		want int64
+	}{{a, 0}, {b, 8}, {c, 16}, {d, -1}} {
+		if got := s.FieldOffsetOf(tt.sym); got != tt.want {
+			t.Errorf("FieldOffsetOf(%s) = %d, want %d", tt.sym.Name, got, tt.want)
+		}
+	}
+	if got := typ(TINT32).FieldOffsetOf(a); got != -1 {
+		t.Errorf("FieldOffsetOf on int32 = %d, want -1", got)
+	}
+}
+
+// zeroSizedStruct returns struct { a struct{}; b [0]int64 }.
+func zeroSizedStruct() *Type {
+	empty := typ(TSTRUCT)
+	arr := typ(TARRAY)
+	arr.Bound = 0
+	arr.Type = typ(TINT64)
+	a, b := newField(), newField()
+	a.Sym, a.Type = &Sym{Name: "a"}, empty
+	b.Sym, b.Type = &Sym{Name: "b"}, arr
+	s := typ(TSTRUCT)
+	s.SetFields([]*Field{a, b})
+	return s
+}
+
+func TestCheckwidthSized(t *testing.T) {
+	defer setWidths()()
+
+	s := zeroSizedStruct()
+	dowidth(s)
+	if s.Width != 0 || s.Align == 0 {
+		t.Fatalf("zero-sized struct has width %d, align %d", s.Width, s.Align)
+	}
+
+	// With width calculations deferred, an already sized
+	// type must not be queued for another calculation.
+	defercalc++
+	checkwidth(s)
+	defercalc--
+	if s.Deferwidth || len(deferredTypeStack) != 0 {
+		deferredTypeStack = deferredTypeStack[:0]
+		t.Errorf("checkwidth deferred an already sized type")
+	}
+}
+
+func BenchmarkCheckwidthSized(b *testing.B) {
+	defer setWidths()()
+
+	var fields []*Field
+	for i := 0; i < 100; i++ {
+		f := newField()
+		f.Sym = &Sym{Name: "f"}
+		f.Type = zeroSizedStruct()
+		fields = append(fields, f)
+	}
+	s := typ(TSTRUCT)
+	s.SetFields(fields)
+	dowidth(s)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		checkwidth(s)
+		for _, f := range fields {
+			dowidth(f.Type)
+		}
+	}
+}
+
+func TestFieldAlign(t *testing.T) {
+	defer setWidths()()
+
+	var fields []*Field
+	for _, et := range []EType{TINT8, TINT64, TINT8} {
+		f := newField()
+		f.Type = typ(et)
+		fields = append(fields, f)
+	}
+	s := typ(TSTRUCT)
+	s.SetFields(fields)
+
+	// struct { int8; int64; int8 }
+	for i, want := range []int64{1, 8, 1} {
+		if got := fields[i].Align(); got != want {
+			t.Errorf("field %d: Align() = %d, want %d", i, got, want)
+		}
+	}
+	if got := s.MaxFieldAlign(); got != 8 {
+		t.Errorf("MaxFieldAlign() = %d, want 8", got)
+	}
+	if got := typ(TSTRUCT).MaxFieldAlign(); got != 0 {
+		t.Errorf("MaxFieldAlign on empty struct = %d, want 0", got)
+	}
+}
+
+func TestIsIncomplete(t *testing.T) {
+	broke := typ(TSTRUCT)
+	broke.Broke = true
+	tests := []struct {
+		name string
+		t    *Type
+		want bool
+	}{
+		{"forward", typ(TFORW), true},
+		{"broke", broke, true},
+		{"int", typ(TINT), false},
+		{"struct", typ(TSTRUCT), false},
+	}
+	for _, tt := range tests {
+		if got := tt.t.IsIncomplete(); got != tt.want {
+			t.Errorf("%s: IsIncomplete() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyType(t *testing.T) {
+	defer setWidths()()
+
+	newStruct := func() (*Type, []*Field) {
+		a, b := newField(), newField()
+		a.Sym, a.Type = &Sym{Name: "a"}, typ(TINT8)
+		b.Sym, b.Type = &Sym{Name: "b"}, typ(TINT64)
+		s := typ(TSTRUCT)
+		s.SetFields([]*Field{a, b})
+		dowidth(s)
+		return s, []*Field{a, b}
+	}
+
+	s, _ := newStruct()
+	m := typ(TMAP)
+	bucket := typ(TSTRUCT)
+	m.Bucket = bucket
+	bucket.Map = m
+	for _, x := range []*Type{typ(TINT), s, m, bucket} {
+		if err := VerifyType(x); err != nil {
+			t.Errorf("VerifyType: %v", err)
+		}
+	}
+
+	overlap, fields := newStruct()
+	fields[1].Offset = 0
+	unsettled, _ := newStruct()
+	unsettled.Width = -2
+	orig := typ(TINT)
+	orig.Orig = typ(TSTRING)
+	badmap := typ(TMAP)
+	badmap.Bucket = typ(TSTRUCT)
+	badmap.Bucket.Map = typ(TMAP)
+	tests := []struct {
+		name string
+		t    *Type
+	}{
+		{"overlapping fields", overlap},
+		{"unsettled width", unsettled},
+		{"mismatched Orig", orig},
+		{"map without back-link", badmap},
+		{"stray back-link", badmap.Bucket},
+	}
+	for _, tt := range tests {
+		if err := VerifyType(tt.t); err == nil {
+			t.Errorf("%s: VerifyType succeeded, want error", tt.name)
+		}
+	}
+}
+
+func TestIsEmptyInterface(t *testing.T) {
+	named := typ(TINTER)
+	named.Sym = &Sym{Name: "Any"}
+	m := newField()
+	m.Sym = &Sym{Name: "M"}
+	m.Type = funcType(false)
+	nonempty := typ(TINTER)
+	nonempty.SetFields([]*Field{m})
+
+	tests := []struct {
+		name string
+		t    *Type
+		want bool
+	}{
+		{"interface{}", typ(TINTER), true},
+		{"named empty interface", named, true},
+		{"non-empty interface", nonempty, false},
+		{"struct", typ(TSTRUCT), false},
+	}
+	for _, tt := range tests {
+		if got := tt.t.IsEmptyInterface(); got != tt.want {
+			t.Errorf("%s: IsEmptyInterface() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUnderlying(t *testing.T) {
+	// named mimics copytype, which declares a named type
+	// by copying the type it is defined as.
+	named := func(name string, def *Type) *Type {
+		nt := *def
+		nt.Sym = &Sym{Name: name}
+		return &nt
+	}
+
+	slice := typ(TARRAY)
+	slice.Bound = -1
+	slice.Type = typ(TINT)
+	strct := typ(TSTRUCT)
+	integer := typ(TINT)
+	integer.Sym = &Sym{Name: "int"}
+	myint := named("MyInt", integer)
+
+	// type List struct { next *List }
+	list := typ(TSTRUCT)
+	ptr := typ(TPTR64)
+	next := newField()
+	next.Sym = &Sym{Name: "next"}
+	next.Type = ptr
+	list.SetFields([]*Field{next})
+	namedList := named("List", list)
+	ptr.Type = namedList
+
+	tests := []struct {
+		name string
+		t    *Type
+		want *Type
+	}{
+		{"[]int", slice, slice},
+		{"named slice", named("S", slice), slice},
+		{"named struct", named("T", strct), strct},
+		{"int", integer, integer},
+		{"type MyInt int", myint, integer},
+		{"type YourInt MyInt", named("YourInt", myint), integer},
+		{"recursive", namedList, list},
+	}
+	for _, tt := range tests {
+		got := tt.t.Underlying()
+		if got != tt.want {
+			t.Errorf("%s: Underlying() = %p, want %p", tt.name, got, tt.want)
+		}
+		if again := got.Underlying(); again != got {
+			t.Errorf("%s: Underlying() is not idempotent", tt.name)
+		}
+	}
+}
+
+func TestFieldPadding(t *testing.T) {
+	defer setWidths()()
+
+	var fields []*Field
+	for _, et := range []EType{TINT8, TINT64, TINT8, TINT16} {
+		f := newField()
+		f.Type = typ(et)
+		fields = append(fields, f)
+	}
+	s := typ(TSTRUCT)
+	s.SetFields(fields)
+
+	// struct { int8; int64; int8; int16 }
+	got := s.FieldPadding()
+	want := []int64{0, 7, 0, 1}
+	if len(got) != len(want) {
+		t.Fatalf("FieldPadding() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("FieldPadding() = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestIsComparable(t *testing.T) {
+	defer setWidths()()
+
+	slice := typ(TARRAY)
+	slice.Bound = -1
+	slice.Type = typ(TINT)
+	funcs := typ(TARRAY)
+	funcs.Bound = 2
+	funcs.Type = funcType(false)
+
+	tests := []struct {
+		name string
+		t    *Type
+		want bool
+	}{
+		{"int", typ(TINT), true},
+		{"string", typ(TSTRING), true},
+		{"interface{}", typ(TINTER), true},
+		{"[]int", slice, false},
+		{"map", typ(TMAP), false},
+		{"func()", funcType(false), false},
+		{"[2]func()", funcs, false},
+		{"struct{int; string}", structOf(typ(TINT), typ(TSTRING)), true},
+		{"struct{int; []int}", structOf(typ(TINT), slice), false},
+	}
+	for _, tt := range tests {
+		if got := tt.t.IsComparable(); got != tt.want {
+			t.Errorf("%s: IsComparable() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsNilable(t *testing.T) {
+	slice := typ(TARRAY)
+	slice.Bound = -1
+	slice.Type = typ(TINT)
+	array := typ(TARRAY)
+	array.Bound = 4
+	array.Type = typ(TINT)
+
+	tests := []struct {
+		name string
+		t    *Type
+		want bool
+	}{
+		{"*int", typ(TPTR64), true},
+		{"unsafe.Pointer", typ(TUNSAFEPTR), true},
+		{"[]int", slice, true},
+		{"map", typ(TMAP), true},
+		{"chan", typ(TCHAN), true},
+		{"func()", funcType(false), true},
+		{"interface{}", typ(TINTER), true},
+		{"int", typ(TINT), false},
+		{"string", typ(TSTRING), false},
+		{"[4]int", array, false},
+		{"struct{}", typ(TSTRUCT), false},
+	}
+	for _, tt := range tests {
+		if got := tt.t.IsNilable(); got != tt.want {
+			t.Errorf("%s: IsNilable() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestChanDir(t *testing.T) {
+	chanType := func(dir uint8) *Type {
+		c := typ(TCHAN)
+		c.Type = typ(TINT)
+		c.Chan = dir
+		return c
+	}
+
+	tests := []struct {
+		name               string
+		t                  *Type
+		dir                ChanDir
+		recvOnly, sendOnly bool
+	}{
+		{"chan int", chanType(Cboth), BothDir, false, false},
+		{"<-chan int", chanType(Crecv), RecvOnly, true, false},
+		{"chan<- int", chanType(Csend), SendOnly, false, true},
+	}
+	for _, tt := range tests {
+		if got := tt.t.ChanDir(); got != tt.dir {
+			t.Errorf("%s: ChanDir() = %v, want %v", tt.name, got, tt.dir)
+		}
+		if got := tt.t.IsRecvOnly(); got != tt.recvOnly {
+			t.Errorf("%s: IsRecvOnly() = %v, want %v", tt.name, got, tt.recvOnly)
+		}
+		if got := tt.t.IsSendOnly(); got != tt.sendOnly {
+			t.Errorf("%s: IsSendOnly() = %v, want %v", tt.name, got, tt.sendOnly)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	// type List struct { val int; next *List; m map[string]*List }
+	list := typ(TSTRUCT)
+	ptr := typ(TPTR64)
+	ptr.Type = list
+	m := typ(TMAP)
+	m.Down = typ(TSTRING)
+	m.Type = ptr
+	var fields []*Field
+	for _, ft := range []*Type{typ(TINT), ptr, m} {
+		f := newField()
+		f.Type = ft
+		fields = append(fields, f)
+	}
+	list.SetFields(fields)
+
+	visited := make(map[*Type]int)
+	ptr.Walk(func(t *Type) bool {
+		visited[t]++
+		return true
+	})
+	if len(visited) != 5 {
+		t.Errorf("walk visited %d types, want 5", len(visited))
+	}
+	for vt, n := range visited {
+		if n != 1 {
+			t.Errorf("%v visited %d times", vt, n)
+		}
+	}
+
+	n := 0
+	ptr.Walk(func(t *Type) bool {
+		n++
+		return t != list
+	})
+	if n != 2 {
+		t.Errorf("pruned walk visited %d types, want 2", n)
+	}
+}
+
+func TestIsNamed(t *testing.T) {
+	if builtinpkg == nil {
+		builtinpkg = mkpkg("go.builtin")
+	}
+	if localpkg == nil {
+		localpkg = mkpkg("")
+	}
+	if unsafepkg == nil {
+		unsafepkg = mkpkg("unsafe")
+	}
+
+	// Mimic copytype: a defined type is a copy of its type literal.
+	named := typ(TSTRUCT)
+	*named = *typ(TSTRUCT)
+	named.Sym = &Sym{Name: "T", Pkg: localpkg}
+	forward := typ(TFORW)
+	forward.Sym = &Sym{Name: "F", Pkg: localpkg}
+	predeclared := typ(TINT)
+	predeclared.Sym = &Sym{Name: "int", Pkg: builtinpkg}
+	errtype := typ(TINTER)
+	errtype.Sym = &Sym{Name: "error", Pkg: builtinpkg}
+	unsafeptr := typ(TUNSAFEPTR)
+	unsafeptr.Sym = &Sym{Name: "Pointer", Pkg: unsafepkg}
+	// A type whose Sym was never moved to builtinpkg is still
+	// predeclared if it is the universe's type for its kind.
+	universe := typ(TFLOAT64)
+	universe.Sym = &Sym{Name: "float64", Pkg: localpkg}
+	saved := Types[TFLOAT64]
+	Types[TFLOAT64] = universe
+	defer func() { Types[TFLOAT64] = saved }()
+	anon := typ(TSTRUCT)
+
+	tests := []struct {
+		name string
+		t    *Type
+		want bool
+	}{
+		{"T", named, true},
+		{"F", forward, true},
+		{"int", predeclared, false},
+		{"error", errtype, false},
+		{"unsafe.Pointer", unsafeptr, false},
+		{"float64", universe, false},
+		{"struct{}", anon, false},
+	}
+	for _, tt := range tests {
+		if got := tt.t.IsNamed(); got != tt.want {
+			t.Errorf("%s: IsNamed() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReceiverBase(t *testing.T) {
+	defer setPtrs()()
+
+	named := typ(TSTRUCT)
+	named.Sym = &Sym{Name: "T"}
+	ptr := typ(TPTR64)
+	ptr.Type = named
+	anon := typ(TSTRUCT)
+	anonptr := typ(TPTR64)
+	anonptr.Type = anon
+
+	tests := []struct {
+		name string
+		t    *Type
+		want *Type
+	}{
+		{"T", named, named},
+		{"*T", ptr, named},
+		{"struct{}", anon, nil},
+		{"*struct{}", anonptr, nil},
+	}
+	for _, tt := range tests {
+		if got := tt.t.ReceiverBase(); got != tt.want {
+			t.Errorf("%s: ReceiverBase() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMethods(t *testing.T) {
+	defer setPtrs()()
+
+	method := func(name string) *Field {
+		f := newField()
+		f.Sym = &Sym{Name: name}
+		f.Type = funcType(false)
+		return f
+	}
+	iface := typ(TINTER)
+	iface.SetFields([]*Field{method("Read"), method("Write")})
+	named := typ(TSTRUCT)
+	named.Sym = &Sym{Name: "T"}
+	named.Methods().Set([]*Field{method("M")})
+	ptr := typ(TPTR64)
+	ptr.Type = named
+
+	tests := []struct {
+		name string
+		t    *Type
+		want []string
+	}{
+		{"interface", iface, []string{"Read", "Write"}},
+		{"T", named, []string{"M"}},
+		{"*T", ptr, []string{"M"}},
+		{"interface{}", typ(TINTER), nil},
+	}
+	for _, tt := range tests {
+		if got := tt.t.NumMethods(); got != len(tt.want) {
+			t.Errorf("%s: NumMethods() = %d, want %d", tt.name, got, len(tt.want))
+			continue
+		}
+		for i, name := range tt.want {
+			if got := tt.t.Method(i).Sym.Name; got != name {
+				t.Errorf("%s: Method(%d) = %s, want %s", tt.name, i, got, name)
+			}
+		}
+	}
+}
+
+func TestComplexElem(t *testing.T) {
+	for _, et := range []EType{TFLOAT32, TFLOAT64} {
+		if Types[et] == nil {
+			Types[et] = typ(et)
+		}
+	}
+
+	tests := []struct {
+		t    *Type
+		want *Type
+	}{
+		{typ(TCOMPLEX64), Types[TFLOAT32]},
+		{typ(TCOMPLEX128), Types[TFLOAT64]},
+		{typ(TFLOAT64), nil},
+	}
+	for _, tt := range tests {
+		if got := tt.t.ComplexElem(); got != tt.want {
+			t.Errorf("%v: ComplexElem() = %v, want %v", Econv(tt.t.Etype), got, tt.want)
+		}
+	}
+}
+
+func TestIsTuple(t *testing.T) {
+	tuple := func(funarg bool, types ...*Type) *Type {
+		t := typ(TSTRUCT)
+		t.Funarg = funarg
+		var fields []*Field
+		for _, ft := range types {
+			f := newField()
+			f.Type = ft
+			fields = append(fields, f)
+		}
+		t.SetFields(fields)
+		return t
+	}
+	i := typ(TINT)
+
+	tests := []struct {
+		name string
+		t    *Type
+		want bool
+	}{
+		{"(int, int)", tuple(true, i, i), true},
+		{"(int)", tuple(true, i), false},
+		{"struct{int; int}", tuple(false, i, i), false},
+	}
+	for _, tt := range tests {
+		if got := tt.t.IsTuple(); got != tt.want {
+			t.Errorf("%s: IsTuple() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+var wideStructSyms []*Sym
+
+// wideStruct returns an unnamed struct type with n fields
+// alternating between types a and b.
+func wideStruct(n int, a, b *Type) *Type {
+	for len(wideStructSyms) < n {
+		wideStructSyms = append(wideStructSyms, &Sym{Name: fmt.Sprintf("f%d", len(wideStructSyms))})
+	}
+	var fields []*Field
+	for i := 0; i < n; i++ {
+		f := newField()
+		f.Sym = wideStructSyms[i]
+		f.Type = a
+		if i%2 != 0 {
+			f.Type = b
+		}
+		fields = append(fields, f)
+	}
+	s := typ(TSTRUCT)
+	s.SetFields(fields)
+	return s
+}
+
+func TestEqtypeCache(t *testing.T) {
+	defer setWidths()()
+	defer func(c map[eqtypeKey]bool) { eqtypeCache = c }(eqtypeCache)
+	eqtypeCache = nil
+
+	// type A struct { next *A }, built twice.
+	recursive := func() *Type {
+		a := typ(TSTRUCT)
+		a.Sym = &Sym{Name: "A"}
+		p := typ(TPTR64)
+		p.Type = a
+		f := newField()
+		f.Sym = &Sym{Name: "next"}
+		f.Type = p
+		a.SetFields([]*Field{f})
+		return p
+	}
+	i, a1, a2 := typ(TINT64), recursive(), recursive()
+
+	s1, s2 := wideStruct(10, i, a1), wideStruct(10, i, a1)
+	s3 := wideStruct(10, i, a2)
+	unsized := wideStruct(10, i, a1)
+	for _, s := range []*Type{s1, s2, s3} {
+		dowidth(s)
+	}
+
+	tests := []struct {
+		name string
+		a, b *Type
+		want bool
+	}{
+		{"s1, s2", s1, s2, true},
+		{"s1, s3", s1, s3, false},
+		{"s1, unsized", s1, unsized, true},
+	}
+	for _, tt := range tests {
+		// The second comparison is answered by the cache.
+		for pass := 0; pass < 2; pass++ {
+			if got := Eqtype(tt.a, tt.b); got != tt.want {
+				t.Errorf("pass %d: Eqtype(%s) = %v, want %v", pass, tt.name, got, tt.want)
+			}
+		}
+	}
+	if len(eqtypeCache) != 2 {
+		t.Errorf("cache has %d entries, want 2", len(eqtypeCache))
+	}
+	if eq, ok := eqtypeCache[eqtypeKey{s1, s3, true}]; !ok || eq {
+		t.Errorf("cache entry for s1, s3 = %v, %v, want false, true", eq, ok)
+	}
+}
+
+func TestEqtypeCacheForward(t *testing.T) {
+	defer setWidths()()
+	defer func(c map[eqtypeKey]bool) { eqtypeCache = c }(eqtypeCache)
+	eqtypeCache = nil
+
+	// type F, declared but not yet defined, used by two
+	// identical struct literals whose widths are deferred.
+	f := typ(TFORW)
+	f.Sym = &Sym{Name: "F"}
+	s1 := structOf(f)
+	s2 := s1.Copy()
+	defercheckwidth()
+	checkwidth(s1)
+	checkwidth(s2)
+
+	if !Eqtype(s1, s2) {
+		t.Errorf("Eqtype(s1, s2) = false before F is resolved, want true")
+	}
+	if len(eqtypeCache) != 0 {
+		t.Errorf("cache has %d entries before F is resolved, want 0", len(eqtypeCache))
+	}
+
+	// type F struct { x int64 }, defined in place as copytype does.
+	sym := f.Sym
+	*f = *structOf(typ(TINT64))
+	f.Sym = sym
+	resumecheckwidth()
+
+	if !Eqtype(s1, s2) {
+		t.Errorf("Eqtype(s1, s2) = false after F is resolved, want true")
+	}
+	if eq, ok := eqtypeCache[eqtypeKey{s1, s2, true}]; !ok || !eq {
+		t.Errorf("cache entry for s1, s2 = %v, %v, want true, true", eq, ok)
+	}
+}
+
+func BenchmarkEqtypeWideStruct(b *testing.B) {
+	defer setWidths()()
+
+	elem := wideStruct(20, typ(TINT32), typ(TFLOAT64))
+	s1, s2 := wideStruct(200, elem, typ(TINT64)), wideStruct(200, elem, typ(TINT64))
+	dowidth(s1)
+	dowidth(s2)
+
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Eqtype(s1, s2)
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			eqtype1(s1, s2, true, nil)
+		}
+	})
+}
+
+func TestIdentical(t *testing.T) {
+	i := typ(TINT)
+	structType := func(sym *Sym) *Type {
+		t := typ(TSTRUCT)
+		t.Sym = sym
+		f := newField()
+		f.Type = i
+		t.SetFields([]*Field{f})
+		return t
+	}
+	anon1 := structType(nil)
+	anon2 := structType(nil)
+	named1 := structType(&Sym{Name: "T"})
+	named2 := structType(&Sym{Name: "U"})
+
+	tests := []struct {
+		name string
+		a, b *Type
+		want bool
+	}{
+		{"struct{int}, struct{int}", anon1, anon2, true},
+		{"T, T", named1, named1, true},
+		{"T, struct{int}", named1, anon1, false},
+		{"T, U", named1, named2, false},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Identical(tt.b); got != tt.want {
+			t.Errorf("Identical(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDeepEqual(t *testing.T) {
+	i := typ(TINT)
+	ptrTo := func(elem *Type) *Type {
+		p := typ(TPTR64)
+		p.Type = elem
+		return p
+	}
+	field := func(name string, ft *Type) *Field {
+		f := newField()
+		f.Sym = &Sym{Name: name}
+		f.Type = ft
+		return f
+	}
+	// recursive builds type A struct{ b *B }; type B struct{ a *A }.
+	recursive := func() *Type {
+		a, b := typ(TSTRUCT), typ(TSTRUCT)
+		a.Sym, b.Sym = &Sym{Name: "A"}, &Sym{Name: "B"}
+		a.SetFields([]*Field{field("b", ptrTo(b))})
+		b.SetFields([]*Field{field("a", ptrTo(a))})
+		return a
+	}
+	// list builds type name struct{ v int; next *name }.
+	list := func(name string) *Type {
+		l := typ(TSTRUCT)
+		l.Sym = &Sym{Name: name}
+		l.SetFields([]*Field{field("v", i), field("next", ptrTo(l))})
+		return l
+	}
+	anon := func(name string) *Type {
+		s := typ(TSTRUCT)
+		s.SetFields([]*Field{field(name, ptrTo(i))})
+		return s
+	}
+
+	tests := []struct {
+		name string
+		a, b *Type
+		want bool
+	}{
+		{"struct{x *int}, struct{x *int}", anon("x"), anon("x"), true},
+		{"struct{x *int}, struct{y *int}", anon("x"), anon("y"), false},
+		{"A, A", recursive(), recursive(), true},
+		{"L, L", list("L"), list("L"), true},
+		{"L, M", list("L"), list("M"), false},
+		{"A, L", recursive(), list("A"), false},
+	}
+	for _, tt := range tests {
+		if got := tt.a.DeepEqual(tt.b); got != tt.want {
+			t.Errorf("DeepEqual(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+		if tt.want && tt.a.Identical(tt.b) && tt.a.Sym != nil {
+			t.Errorf("Identical(%s) = true for distinct named types", tt.name)
+		}
+	}
+}
+
+func TestBaseName(t *testing.T) {
+	imported := typ(TSTRUCT)
+	imported.Sym = &Sym{Name: "Buffer", Pkg: mkpkg("bytes")}
+	local := typ(TINT)
+	local.Sym = &Sym{Name: "T", Pkg: localpkg}
+	slice := typ(TARRAY)
+	slice.Bound = -1
+	slice.Type = local
+
+	tests := []struct {
+		t    *Type
+		want string
+	}{
+		{imported, "Buffer"},
+		{local, "T"},
+		{typ(TSTRUCT), "struct"},
+		{slice, "slice"},
+	}
+	for _, tt := range tests {
+		if got := tt.t.BaseName(); got != tt.want {
+			t.Errorf("BaseName() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+// funcType returns the func type func(params...) with
+// the final parameter variadic if ddd is set.
+func funcType(ddd bool, params ...*Type) *Type {
+	funarg := func(types ...*Type) *Type {
+		t := typ(TSTRUCT)
+		t.Funarg = true
+		var fields []*Field
+		for _, ft := range types {
+			f := newField()
+			f.Type = ft
+			fields = append(fields, f)
+		}
+		t.SetFields(fields)
+		return t
+	}
+	recvs, ps, results := funarg(), funarg(params...), funarg()
+	if ddd && len(params) > 0 {
+		ps.Field(len(params) - 1).Isddd = true
+	}
+	t := typ(TFUNC)
+	t.Type = recvs
+	recvs.Down = results
+	results.Down = ps
+	return t
+}
+
+func TestFuncAssignableTo(t *testing.T) {
+	slice := typ(TARRAY)
+	slice.Bound = -1
+	slice.Type = typ(TINT)
+
+	variadic := funcType(true, slice)  // func(...int)
+	plain := funcType(false, slice)    // func([]int)
+	variadic2 := funcType(true, slice) // func(...int)
+
+	tests := []struct {
+		name string
+		a, b *Type
+		want bool
+	}{
+		{"func(...int) to func([]int)", variadic, plain, false},
+		{"func([]int) to func(...int)", plain, variadic, false},
+		{"func(...int) to func(...int)", variadic, variadic2, true},
+		{"func([]int) to []int", plain, slice, false},
+	}
+	for _, tt := range tests {
+		if got := tt.a.FuncAssignableTo(tt.b); got != tt.want {
+			t.Errorf("%s: FuncAssignableTo() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFieldsWithTags(t *testing.T) {
+	tag := `json:"name,omitempty"`
+	tagged := newField()
+	tagged.Sym = &Sym{Name: "Name"}
+	tagged.Type = typ(TSTRING)
+	tagged.Note = &tag
+	untagged := newField()
+	untagged.Sym = &Sym{Name: "age"}
+	untagged.Type = typ(TINT)
+
+	s := typ(TSTRUCT)
+	s.SetFields([]*Field{tagged, untagged})
+
+	got := s.FieldsWithTags()
+	if len(got) != 2 {
+		t.Fatalf("FieldsWithTags() returned %d fields, want 2", len(got))
+	}
+	want := []FieldTag{
+		{Field: tagged, Tag: tag},
+		{Field: untagged},
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("field %d = %v %q, want %v %q", i, got[i].Field, got[i].Tag, want[i].Field, want[i].Tag)
+		}
+	}
+	if got := typ(TINT).FieldsWithTags(); got != nil {
+		t.Errorf("FieldsWithTags() on int = %v, want nil", got)
+	}
+}
+
+func TestWithoutReceiver(t *testing.T) {
+	recv := typ(TSTRUCT)
+	recv.Sym = &Sym{Name: "T"}
+	str := typ(TSTRING)
+
+	m := funcType(true, typ(TINT), str) // func (T) m(int, ...string)
+	rf := newField()
+	rf.Type = recv
+	m.Recvs().SetFields([]*Field{rf})
+
+	f := m.WithoutReceiver()
+	if f.Etype != TFUNC {
+		t.Fatalf("WithoutReceiver() = %v, want TFUNC", Econv(f.Etype))
+	}
+	if f.Recv() != nil {
+		t.Errorf("WithoutReceiver() has receiver %v", f.Recv().Type)
+	}
+	if got, want := f.Params().NumFields(), 2; got != want {
+		t.Fatalf("WithoutReceiver() has %d params, want %d", got, want)
+	}
+	for i, p := range f.Params().Fields().Slice() {
+		mp := m.Params().Field(i)
+		if p == mp {
+			t.Errorf("param %d shared with method type", i)
+		}
+		if p.Type != mp.Type || p.Isddd != mp.Isddd {
+			t.Errorf("param %d = %v (ddd %v), want %v (ddd %v)", i, Econv(p.Type.Etype), p.Isddd, Econv(mp.Type.Etype), mp.Isddd)
+		}
+	}
+	if f.Results().NumFields() != 0 {
+		t.Errorf("WithoutReceiver() has %d results, want 0", f.Results().NumFields())
+	}
+	if m.Recv() == nil || m.Recv().Type != recv {
+		t.Errorf("method type lost its receiver")
+	}
+}
+
+func TestCompareDDDField(t *testing.T) {
+	ddd := func(elem *Type) *Type {
+		d := typ(TDDDFIELD)
+		d.Type = elem
+		return d
+	}
+	i8, i16 := typ(TINT8), typ(TINT16)
+
+	if got := ddd(i8).Compare(ddd(i8)); got != ssa.CMPeq {
+		t.Errorf("...int8 vs ...int8: Compare() = %v, want %v", got, ssa.CMPeq)
+	}
+	lt, gt := ddd(i8).Compare(ddd(i16)), ddd(i16).Compare(ddd(i8))
+	if lt != ssa.CMPlt || gt != ssa.CMPgt {
+		t.Errorf("...int8 vs ...int16: Compare() = %v, %v, want %v, %v", lt, gt, ssa.CMPlt, ssa.CMPgt)
+	}
+	if got := ddd(i8).Compare(i8); got != ssa.CMPgt {
+		t.Errorf("...int8 vs int8: Compare() = %v, want %v", got, ssa.CMPgt)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"unicode/utf8"
 )
 
 // type check the whole tree of an expression.
@@ -186,6 +187,18 @@ func typecheck(n *Node, top int) *Node {
 	return n
 }
 
+// isrealnumeric reports whether t is a typed integer or
+// floating-point type.
+func isrealnumeric(t *Type) bool {
+	return t != nil && t.Etype != TIDEAL && (Isint[t.Etype] || Isfloat[t.Etype])
+}
+
+// isboolconstname reports whether n is a reference to a named
+// boolean constant, such as true or a user-defined flag.
+func isboolconstname(n *Node) bool {
+	return n.Op == OLITERAL && n.Sym != nil && n.Val().Ctype() == CTBOOL
+}
+
 // does n contain a call or receive operation?
 func callrecv(n *Node) bool {
 	if n == nil {
@@ -219,6 +232,42 @@ func callrecvlist(l Nodes) bool {
 	return false
 }
 
+// IsPure reports whether evaluating n has no side effects:
+// n contains no calls, receives, sends, or assignments.
+func IsPure(n *Node) bool {
+	return !callrecv(n) && !assigns(n)
+}
+
+// does n contain a send or assignment?
+func assigns(n *Node) bool {
+	if n == nil {
+		return false
+	}
+
+	switch n.Op {
+	case OSEND,
+		OAS,
+		OAS2,
+		OAS2FUNC,
+		OAS2RECV,
+		OAS2MAPR,
+		OAS2DOTTYPE,
+		OASOP:
+		return true
+	}
+
+	return assigns(n.Left) || assigns(n.Right) || assignslist(n.Ninit) || assignslist(n.Nbody) || assignslist(n.List) || assignslist(n.Rlist)
+}
+
+func assignslist(l Nodes) bool {
+	for _, n := range l.Slice() {
+		if assigns(n) {
+			return true
+		}
+	}
+	return false
+}
+
 // indexlit implements typechecking of untyped values as
 // array/slice indexes. It is equivalent to defaultlit
 // except for constants of numerical kind, which are acceptable
@@ -403,6 +452,9 @@ OpSwitch:
 		n.Type = maptype(l.Type, r.Type)
 		n.Left = nil
 		n.Right = nil
+		if hinting() {
+			largemapqueue = append(largemapqueue, n)
+		}
 
 	case OTCHAN:
 		ok |= Etype
@@ -568,6 +620,14 @@ OpSwitch:
 			break OpSwitch
 		}
 
+		if iscmp[n.Op] {
+			if c, t := cmpoverflow(l, r); c != nil {
+				Yyerror("constant %s overflows %v in comparison", Vconv(c.Val(), 0), t)
+				n.Type = nil
+				return n
+			}
+		}
+
 		// ideal mixed with non-ideal
 		l, r = defaultlit2(l, r, false)
 
@@ -644,54 +704,86 @@ OpSwitch:
 		if t.Etype != TIDEAL && !Eqtype(l.Type, r.Type) {
 			l, r = defaultlit2(l, r, true)
 			if Isinter(r.Type) == Isinter(l.Type) || aop == 0 {
-				Yyerror("invalid operation: %v (mismatched types %v and %v)", n, l.Type, r.Type)
+				why := ""
+				if reorderedfields(l.Type, r.Type) {
+					why = fmt.Sprintf(":\n\tstructs %v and %v have the same fields in different order", l.Type, r.Type)
+				} else if isrealnumeric(l.Type) && isrealnumeric(r.Type) {
+					// Suggest converting the operand whose type
+					// has the lower rank, so no precision is lost.
+					x, to := r, l.Type
+					if l.Type.NumericRank() < r.Type.NumericRank() {
+						x, to = l, r.Type
+					}
+					why = fmt.Sprintf(" (use a conversion such as %v(%v))", to, x)
+				} else if iscmp[n.Op] && Isinter(l.Type) != Isinter(r.Type) && l.Type.Etype != TNIL && r.Type.Etype != TNIL {
+					// A concrete value can only equal an interface
+					// value holding its type; say why it cannot.
+					c, iface := r.Type, l.Type
+					if Isinter(r.Type) {
+						c, iface = l.Type, r.Type
+					}
+					if assignop(c, iface, &why) == 0 && why != "" {
+						why = ":\n\timpossible comparison: " + strings.TrimPrefix(why, ":\n\t")
+					} else {
+						why = ""
+					}
+				}
+				Yyerror("invalid operation: %v (mismatched types %v and %v)%s", n, Tconv(l.Type, FmtTrunc), Tconv(r.Type, FmtTrunc), why)
 				n.Type = nil
 				return n
 			}
 		}
 
-		if !okfor[op][et] {
-			Yyerror("invalid operation: %v (operator %v not defined on %s)", n, Oconv(op, 0), typekind(t))
+		if iscmp[n.Op] && t.Etype == TNIL {
+			Yyerror("invalid operation: %v (comparison of two untyped nil values)", n)
 			n.Type = nil
 			return n
 		}
 
-		// okfor allows any array == array, map == map, func == func.
-		// restrict to slice/map/func == nil and nil == slice/map/func.
-		if Isfixedarray(l.Type) && algtype1(l.Type, nil) == ANOEQ {
-			Yyerror("invalid operation: %v (%v cannot be compared)", n, l.Type)
-			n.Type = nil
-			return n
-		}
-
-		if Isslice(l.Type) && !isnil(l) && !isnil(r) {
-			Yyerror("invalid operation: %v (slice can only be compared to nil)", n)
+		if !okfor[op][et] {
+			Yyerror("invalid operation: %v (operator %v not defined on %s)", n, Oconv(op, 0), typekind(t))
 			n.Type = nil
 			return n
 		}
 
-		if l.Type.Etype == TMAP && !isnil(l) && !isnil(r) {
-			Yyerror("invalid operation: %v (map can only be compared to nil)", n)
+		// okfor allows any array == array, map == map, func == func.
+		// restrict to slice/map/func == nil and nil == slice/map/func.
+		if l.Type.IsNilable() && !l.Type.IsComparable() && !isnil(l) && !isnil(r) {
+			Yyerror("invalid operation: %v (%s can only be compared to nil)", n, typekind(l.Type))
 			n.Type = nil
 			return n
 		}
 
-		if l.Type.Etype == TFUNC && !isnil(l) && !isnil(r) {
-			Yyerror("invalid operation: %v (func can only be compared to nil)", n)
-			n.Type = nil
-			return n
+		if hinting() && (n.Op == OEQ || n.Op == ONE) && l.Type.Etype == TCHAN && r.Type.Etype == TCHAN && !isnil(l) && !isnil(r) {
+			Warnl(n.Lineno, "comparing channel identities; channels are equal only if created by the same make")
 		}
 
-		var badtype *Type
-		if l.Type.Etype == TSTRUCT && algtype1(l.Type, &badtype) == ANOEQ {
-			Yyerror("invalid operation: %v (struct containing %v cannot be compared)", n, badtype)
+		if (Isfixedarray(l.Type) || l.Type.Etype == TSTRUCT) && !l.Type.IsComparable() {
+			if l.Type.Etype == TSTRUCT {
+				path, badtype := incomparablefield(l.Type)
+				names := make([]string, len(path))
+				for i, s := range path {
+					names[i] = s.Name
+				}
+				Yyerror("invalid operation: %v (struct field %s of type %v cannot be compared)", n, strings.Join(names, "."), badtype)
+			} else {
+				Yyerror("invalid operation: %v (%v cannot be compared)", n, l.Type)
+			}
 			n.Type = nil
 			return n
 		}
 
 		t = l.Type
 		if iscmp[n.Op] {
+			// Report comparisons that fold to a constant, except in
+			// constant declarations, where they are used as
+			// compile-time assertions, and comparisons involving
+			// named boolean constants, which are typically flags.
+			constcmp := hinting() && top&Eiota == 0 && l.Op == OLITERAL && r.Op == OLITERAL && !isboolconstname(l) && !isboolconstname(r)
 			evconst(n)
+			if constcmp && n.Op == OLITERAL && n.Val().Ctype() == CTBOOL {
+				Warnl(n.Lineno, "comparison is always %v", n.Val().U.(bool))
+			}
 			t = idealbool
 			if n.Op != OLITERAL {
 				l, r = defaultlit2(l, r, true)
@@ -760,7 +852,18 @@ OpSwitch:
 			return n
 		}
 		if !okfor[n.Op][t.Etype] {
-			Yyerror("invalid operation: %v %v", Oconv(n.Op, 0), t)
+			var why string
+			switch n.Op {
+			case OMINUS:
+				why = "unary minus requires a numeric type"
+			case OPLUS:
+				why = "unary plus requires a numeric type"
+			case ONOT:
+				why = "logical not requires a boolean type"
+			case OCOM:
+				why = "bitwise complement requires an integer type"
+			}
+			Yyerror("invalid operation: %v on %v (%s)", Oconv(n.Op, 0), t, why)
 			n.Type = nil
 			return n
 		}
@@ -777,7 +880,11 @@ OpSwitch:
 			n.Type = nil
 			return n
 		}
-		checklvalue(n.Left, "take the address of")
+		if n.Left.Op == ONAME && n.Left.Class == PFUNC {
+			Yyerror("cannot take the address of function %v", n.Left)
+		} else {
+			checklvalue(n.Left, "take the address of")
+		}
 		r := outervalue(n.Left)
 		var l *Node
 		for l = n.Left; l != r; l = l.Left {
@@ -794,6 +901,11 @@ OpSwitch:
 		if l.Name != nil && l.Name.Param != nil && l.Name.Param.Closure != nil {
 			l.Name.Param.Closure.Addrtaken = true
 		}
+		if hinting() && l.Op == ONAME && l.Class == PPARAM && Curfn != nil && Curfn.Type != nil {
+			if rf := Curfn.Type.Recv(); rf != nil && rf.Nname == l && !Isptr[l.Type.Etype] {
+				Warnl(n.Lineno, "address of value receiver; modifications won't affect the caller")
+			}
+		}
 		n.Left = defaultlit(n.Left, nil)
 		l = n.Left
 		t := l.Type
@@ -873,7 +985,9 @@ OpSwitch:
 				return n
 			}
 			n.Op = ODOTPTR
-			checkwidth(t)
+			if !widthSettled(t) {
+				checkwidth(t)
+			}
 		}
 
 		if isblanksym(n.Sym) {
@@ -885,7 +999,7 @@ OpSwitch:
 		if lookdot(n, t, 0) == nil {
 			// Legitimate field or method lookup failed, try to explain the error
 			switch {
-			case isnilinter(t):
+			case t.IsEmptyInterface():
 				Yyerror("%v undefined (type %v is interface with no methods)", n, n.Left.Type)
 
 			case Isptr[t.Etype] && Isinter(t.Type):
@@ -899,6 +1013,8 @@ OpSwitch:
 			default:
 				if mt := lookdot(n, t, 2); mt != nil { // Case-insensitive lookup.
 					Yyerror("%v undefined (type %v has no field or method %v, but does have %v)", n, n.Left.Type, n.Sym, mt.Sym)
+				} else if near := closestfield(t, n.Sym); near != nil {
+					Yyerror("%v undefined (type %v has no field or method %v, but does have %v)", n, n.Left.Type, n.Sym, near)
 				} else {
 					Yyerror("%v undefined (type %v has no field or method %v)", n, n.Left.Type, n.Sym)
 				}
@@ -947,6 +1063,10 @@ OpSwitch:
 			}
 		}
 
+		if hinting() && n.Type != nil && Eqtype(n.Type, t) {
+			Warnl(n.Lineno, "redundant type assertion: %v is already %v", l, t)
+		}
+
 		if n.Type != nil && n.Type.Etype != TINTER {
 			var missing, have *Field
 			var ptr int
@@ -1017,6 +1137,8 @@ OpSwitch:
 					Yyerror("invalid string index %v (out of bounds for %d-byte string)", n.Right, len(n.Left.Val().U.(string)))
 				} else if n.Right.Val().U.(*Mpint).Cmp(Maxintval[TINT]) > 0 {
 					Yyerror("invalid %s index %v (index too large)", why, n.Right)
+				} else if hinting() && Isslice(t) && n.Left.Op == OARRAYLIT && n.Left.Right != nil && x >= n.Left.Right.Int() {
+					Warnl(n.Lineno, "index %v out of range for %d-element slice literal; will panic at run time", n.Right, n.Left.Right.Int())
 				}
 			}
 
@@ -1048,8 +1170,8 @@ OpSwitch:
 			return n
 		}
 
-		if t.Chan&Crecv == 0 {
-			Yyerror("invalid operation: %v (receive from send-only type %v)", n, t)
+		if t.IsSendOnly() {
+			Yyerror("cannot receive from send-only channel %v (declared chan<- %v)", l, t.Type)
 			n.Type = nil
 			return n
 		}
@@ -1075,7 +1197,7 @@ OpSwitch:
 			return n
 		}
 
-		if t.Chan&Csend == 0 {
+		if t.IsRecvOnly() {
 			Yyerror("invalid operation: %v (send to receive-only type %v)", n, t)
 			n.Type = nil
 			return n
@@ -1155,6 +1277,9 @@ OpSwitch:
 			n.Type = nil
 			return n
 		}
+		if n.Op == OSLICESTR && hinting() {
+			checkslicerunes(n, l, lo, hi)
+		}
 		break OpSwitch
 
 	case OSLICE3:
@@ -1269,7 +1394,7 @@ OpSwitch:
 		if l.Op == OTYPE {
 			if n.Isddd || l.Type.isDDDArray() {
 				if !l.Type.Broke {
-					Yyerror("invalid use of ... in type conversion to %v", l.Type)
+					Yyerror("invalid use of ... in %v: ... cannot be used in a conversion", n)
 				}
 				n.Diag = 1
 			}
@@ -1300,7 +1425,9 @@ OpSwitch:
 			n.Type = nil
 			return n
 		}
-		checkwidth(t)
+		if !widthSettled(t) {
+			checkwidth(t)
+		}
 
 		switch l.Op {
 		case ODOTINTER:
@@ -1532,7 +1659,7 @@ OpSwitch:
 			return n
 		}
 
-		if t.Chan&Csend == 0 {
+		if t.IsRecvOnly() {
 			Yyerror("invalid operation: %v (cannot close receive-only channel)", n)
 			n.Type = nil
 			return n
@@ -1615,6 +1742,10 @@ OpSwitch:
 			return n
 		}
 
+		if hinting() && args.Len() == 1 && !n.Isddd && funarg == nil {
+			Warnl(n.Lineno, "append with no elements to add is a no-op")
+		}
+
 		if n.Isddd {
 			if args.Len() == 1 {
 				Yyerror("cannot use ... on first argument to append")
@@ -1753,7 +1884,14 @@ OpSwitch:
 			// do not use stringtoarraylit.
 		// generated code and compiler memory footprint is better without it.
 		case OSTRARRAYBYTE:
-			break
+			if loopbodydepth > 0 && hinting() {
+				Warn("string to []byte conversion in loop allocates each iteration")
+			}
+
+		case OARRAYBYTESTR:
+			if loopbodydepth > 0 && hinting() {
+				Warn("[]byte to string conversion in loop allocates each iteration")
+			}
 
 		case OSTRARRAYRUNE:
 			if n.Left.Op == OLITERAL {
@@ -1824,12 +1962,22 @@ OpSwitch:
 				n.Type = nil
 				return n
 			}
+			if hinting() && r != nil && l.Op == ONAME && r.Op == ONAME && l != r {
+				Warnl(n.Lineno, "make(%v, %v, %v) panics if %v > %v; consider checking len against cap", t, l, r, l, r)
+			}
 
 			n.Left = l
 			n.Right = r
 			n.Op = OMAKESLICE
 
 		case TMAP:
+			// maptype reports invalid keys as it builds t;
+			// check again in case the key was resolved later.
+			if !t.Broke && algtype1(t.Key(), nil) == ANOEQ {
+				Yyerror("invalid map key type %v (not comparable)", t.Key())
+				n.Type = nil
+				return n
+			}
 			if i < len(args) {
 				l = args[i]
 				i++
@@ -1903,6 +2051,15 @@ OpSwitch:
 			return n
 		}
 
+		if hinting() && !t.IsIncomplete() {
+			// Don't force the width of a type whose calculation
+			// is deferred; skip the hint instead.
+			checkwidth(t)
+			if widthSettled(t) && t.Width == 0 {
+				Warnl(n.Lineno, "new(%v) allocates a zero-size type; all such pointers may be equal", t)
+			}
+		}
+
 		n.Left = l
 		n.Type = Ptrto(t)
 		break OpSwitch
@@ -2062,7 +2219,9 @@ OpSwitch:
 			}
 		}
 		n.Right = typecheck(n.Right, Etop)
+		loopbodydepth++
 		typecheckslice(n.Nbody.Slice(), Etop)
+		loopbodydepth--
 		decldepth--
 		break OpSwitch
 
@@ -2096,6 +2255,15 @@ OpSwitch:
 		if Curfn.Type.Outnamed && n.List.Len() == 0 {
 			break OpSwitch
 		}
+		// Counts that differ are reported by typecheckaste.
+		if n.List.Len() == 1 {
+			r := n.List.First()
+			results := Curfn.Type.Results()
+			if r.Type != nil && r.Type.Etype == TSTRUCT && r.Type.Funarg && !results.Broke && r.Type.NumFields() == results.NumFields() && !tupleassignable(r.Type, results) {
+				Yyerror("cannot return result of %v (type %v) from function returning %v", r, r.Type, results)
+				break OpSwitch
+			}
+		}
 		typecheckaste(ORETURN, nil, false, Curfn.Type.Results(), n.List, func() string { return "return argument" })
 		break OpSwitch
 
@@ -2148,8 +2316,10 @@ OpSwitch:
 		break OpSwitch
 	}
 
+	// Most expressions, such as index and selector results, have
+	// types that are already sized; don't queue those again.
 	t := n.Type
-	if t != nil && !t.Funarg && n.Op != OTYPE {
+	if t != nil && !t.Funarg && n.Op != OTYPE && !widthSettled(t) {
 		switch t.Etype {
 		case TFUNC, // might have TANY; wait until its called
 			TANY,
@@ -2190,7 +2360,22 @@ OpSwitch:
 
 	if (top&Etop != 0) && top&(Ecall|Erv|Etype) == 0 && ok&Etop == 0 {
 		if n.Diag == 0 {
-			Yyerror("%v evaluated but not used", n)
+			// Name the declared function, not a function literal in it.
+			fn := Curfn
+			for fn != nil {
+				if fn.Op == ODCLFUNC && fn.Func.Closure != nil {
+					fn = fn.Func.Closure
+				}
+				if fn.Op != OCLOSURE {
+					break
+				}
+				fn = fn.Func.Outerfunc
+			}
+			if fn != nil && fn.Func.Nname != nil {
+				Yyerror("%v evaluated but not used in %v", n, fn.Func.Nname)
+			} else {
+				Yyerror("%v evaluated but not used", n)
+			}
 			n.Diag = 1
 		}
 
@@ -2234,6 +2419,37 @@ func checksliceindex(l *Node, r *Node, tp *Type) bool {
 	return true
 }
 
+// checkslicerunes reports slice bounds lo and hi of the slice n of
+// a constant string l that could split a multibyte UTF-8 sequence.
+func checkslicerunes(n *Node, l *Node, lo *Node, hi *Node) {
+	if !Isconst(l, CTSTR) {
+		return
+	}
+	s := l.Val().U.(string)
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return
+	}
+	for _, b := range []*Node{lo, hi} {
+		if b == nil {
+			continue
+		}
+		if b.Op != OLITERAL {
+			Warnl(n.Lineno, "slice bounds of %v are byte offsets, but it contains multibyte UTF-8 sequences", l)
+			return
+		}
+		if x := nonnegconst(b); x >= 0 && x < len(s) && !utf8.RuneStart(s[x]) {
+			Warnl(n.Lineno, "slicing %v at byte %d splits a multibyte UTF-8 sequence", l, x)
+		}
+	}
+}
+
 func checksliceconst(lo *Node, hi *Node) bool {
 	if lo != nil && hi != nil && lo.Op == OLITERAL && hi.Op == OLITERAL && lo.Val().U.(*Mpint).Cmp(hi.Val().U.(*Mpint)) > 0 {
 		Yyerror("invalid slice index: %v > %v", lo, hi)
@@ -2369,29 +2585,27 @@ func twoarg(n *Node) bool {
 }
 
 func lookdot1(errnode *Node, s *Sym, t *Type, fs *Fields, dostrcmp int) *Field {
-	var r *Field
-	for _, f := range fs.Slice() {
-		if dostrcmp != 0 && f.Sym.Name == s.Name {
-			return f
-		}
-		if dostrcmp == 2 && strings.EqualFold(f.Sym.Name, s.Name) {
-			return f
-		}
-		if f.Sym != s {
-			continue
-		}
-		if r != nil {
-			if errnode != nil {
-				Yyerror("ambiguous selector %v", errnode)
-			} else if Isptr[t.Etype] {
-				Yyerror("ambiguous selector (%v).%v", t, s)
-			} else {
-				Yyerror("ambiguous selector %v.%v", t, s)
+	if dostrcmp != 0 {
+		for _, f := range fs.Slice() {
+			if f.Sym.Name == s.Name {
+				return f
+			}
+			if dostrcmp == 2 && strings.EqualFold(f.Sym.Name, s.Name) {
+				return f
 			}
-			break
 		}
+		return nil
+	}
 
-		r = f
+	r, dup := fs.lookup(s)
+	if dup {
+		if errnode != nil {
+			Yyerror("ambiguous selector %v", errnode)
+		} else if Isptr[t.Etype] {
+			Yyerror("ambiguous selector (%v).%v", t, s)
+		} else {
+			Yyerror("ambiguous selector %v.%v", t, s)
+		}
 	}
 
 	return r
@@ -2564,6 +2778,94 @@ func lookdot(n *Node, t *Type, dostrcmp int) *Field {
 	return nil
 }
 
+// closestfield returns the name of the field or method of t,
+// including promoted ones, that is closest to s by edit distance.
+// It returns nil if no name is close enough or if several names
+// are equally close.
+func closestfield(t *Type, s *Sym) *Sym {
+	var best *Sym
+	bestd := -1
+	ambiguous := false
+	seen := make(map[string]bool)
+	consider := func(f *Field) {
+		if f.Sym == nil || isblanksym(f.Sym) || seen[f.Sym.Name] {
+			return
+		}
+		if !exportname(f.Sym.Name) && f.Sym.Pkg != localpkg {
+			return
+		}
+		seen[f.Sym.Name] = true
+		d := levenshtein(s.Name, f.Sym.Name)
+		switch {
+		case d == 0:
+			// Exact matches were already diagnosed by lookdot.
+		case bestd < 0 || d < bestd:
+			best, bestd, ambiguous = f.Sym, d, false
+		case d == bestd:
+			ambiguous = true
+		}
+	}
+
+	var walk func(t *Type, depth int)
+	walk = func(t *Type, depth int) {
+		if t != nil && Isptr[t.Etype] {
+			t = t.Type
+		}
+		if t == nil || depth > 4 || (t.Etype != TSTRUCT && t.Etype != TINTER) {
+			return
+		}
+		for _, f := range t.Fields().Slice() {
+			consider(f)
+			if f.Embedded != 0 && t.Etype == TSTRUCT {
+				walk(f.Type, depth+1)
+			}
+		}
+	}
+	walk(t, 0)
+
+	if mt := methtype(t, 0); mt != nil {
+		expandmeth(mt)
+		for _, f := range mt.AllMethods().Slice() {
+			consider(f)
+		}
+	}
+
+	if best == nil || ambiguous || bestd >= len(s.Name) {
+		return nil
+	}
+	if bestd > 2 && bestd*10 > len(s.Name)*4 {
+		return nil
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			d := prev[j-1]
+			if a[i-1] != b[j-1] {
+				d++
+			}
+			if prev[j]+1 < d {
+				d = prev[j] + 1
+			}
+			if cur[j-1]+1 < d {
+				d = cur[j-1] + 1
+			}
+			cur[j] = d
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
 func nokeys(l Nodes) bool {
 	for _, n := range l.Slice() {
 		if n.Op == OKEY {
@@ -2583,6 +2885,20 @@ func hasddd(t *Type) bool {
 	return false
 }
 
+// tupleassignable reports whether each field of the result
+// tuple src is assignable to the corresponding field of dst.
+func tupleassignable(src, dst *Type) bool {
+	if src.NumFields() != dst.NumFields() {
+		return false
+	}
+	for i, f := range src.Fields().Slice() {
+		if assignop(f.Type, dst.Field(i).Type, nil) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // typecheck assignment: type list = expression list
 func typecheckaste(op Op, call *Node, isddd bool, tstruct *Type, nl Nodes, desc func() string) {
 	var t *Type
@@ -2634,7 +2950,9 @@ func typecheckaste(op Op, call *Node, isddd bool, tstruct *Type, nl Nodes, desc
 						goto notenough
 					}
 					if assignop(tn.Type, tl.Type, &why) == 0 {
-						if call != nil {
+						if name := argname(tl.Sym); call != nil && name != "" {
+							Yyerror("cannot use %v as type %v in argument %s to %v%s", tn.Type, tl.Type, name, call, why)
+						} else if call != nil {
 							Yyerror("cannot use %v as type %v in argument to %v%s", tn.Type, tl.Type, call, why)
 						} else {
 							Yyerror("cannot use %v as type %v in %s%s", tn.Type, tl.Type, desc(), why)
@@ -2679,6 +2997,10 @@ func typecheckaste(op Op, call *Node, isddd bool, tstruct *Type, nl Nodes, desc
 	i = 0
 	for _, tl := range tstruct.Fields().Slice() {
 		t = tl.Type
+		argdesc := desc
+		if name := argname(tl.Sym); call != nil && name != "" {
+			argdesc = func() string { return fmt.Sprintf("argument %s to %v", name, call) }
+		}
 		if tl.Isddd {
 			if isddd {
 				if i >= nl.Len() {
@@ -2689,8 +3011,12 @@ func typecheckaste(op Op, call *Node, isddd bool, tstruct *Type, nl Nodes, desc
 				}
 				n = nl.Index(i)
 				setlineno(n)
+				if n.Type != nil && !n.Type.Broke && n.Type.Etype != TNIL && assignop(n.Type, t, nil) == 0 {
+					Yyerror("cannot use %v (type %v) as the last argument with ... (expected %v)", n, n.Type, t)
+					goto out
+				}
 				if n.Type != nil {
-					nl.SetIndex(i, assignconvfn(n, t, desc))
+					nl.SetIndex(i, assignconvfn(n, t, argdesc))
 				}
 				goto out
 			}
@@ -2699,7 +3025,7 @@ func typecheckaste(op Op, call *Node, isddd bool, tstruct *Type, nl Nodes, desc
 				n = nl.Index(i)
 				setlineno(n)
 				if n.Type != nil {
-					nl.SetIndex(i, assignconvfn(n, t.Type, desc))
+					nl.SetIndex(i, assignconvfn(n, t.Type, argdesc))
 				}
 			}
 
@@ -2712,7 +3038,10 @@ func typecheckaste(op Op, call *Node, isddd bool, tstruct *Type, nl Nodes, desc
 		n = nl.Index(i)
 		setlineno(n)
 		if n.Type != nil {
-			nl.SetIndex(i, assignconvfn(n, t, desc))
+			nl.SetIndex(i, assignconvfn(n, t, argdesc))
+			if call != nil {
+				checkargsize(call, n, i, t)
+			}
 		}
 		i++
 	}
@@ -2739,9 +3068,9 @@ notenough:
 			// Method expressions have the form T.M, and the compiler has
 			// rewritten those to ONAME nodes but left T in Left.
 			if call.Op == ONAME && call.Left != nil && call.Left.Op == OTYPE {
-				Yyerror("not enough arguments in call to method expression %v", call)
+				Yyerror("not enough arguments in call to method expression %v%s", call, havewant(isddd, tstruct, nl))
 			} else {
-				Yyerror("not enough arguments in call to %v", call)
+				Yyerror("not enough arguments in call to %v%s", call, havewant(isddd, tstruct, nl))
 			}
 		} else {
 			Yyerror("not enough arguments to %v", Oconv(op, 0))
@@ -2755,27 +3084,100 @@ notenough:
 
 toomany:
 	if call != nil {
-		Yyerror("too many arguments in call to %v", call)
+		Yyerror("too many arguments in call to %v%s", call, havewant(isddd, tstruct, nl))
 	} else {
 		Yyerror("too many arguments to %v", Oconv(op, 0))
 	}
 	goto out
 }
 
+// argname returns the source name of parameter s for use in
+// argument error messages, or "" if s has no usable name.
+func argname(s *Sym) string {
+	if s == nil || isblanksym(s) {
+		return ""
+	}
+	name := s.Name
+	if i := strings.Index(name, "·"); i > 0 {
+		name = name[:i] // cut off numbering of imported parameters
+	}
+	if strings.HasPrefix(name, "~") {
+		return "" // synthetic name for unnamed result or blank parameter
+	}
+	return name
+}
+
+// checkargsize warns, under -d=argsize=N, when argument n, the ith
+// argument to call, passes a value of type t larger than N bytes.
+func checkargsize(call *Node, n *Node, i int, t *Type) {
+	if Debug_argsize <= 0 || !usercode() || t.Broke {
+		return
+	}
+	dowidth(t)
+	if t.Width > int64(Debug_argsize) {
+		// Names carry their declaration line; setlineno keeps
+		// the line of the call for them.
+		lno := setlineno(n)
+		Warnl(lineno, "argument %d to %v has type %v of %d bytes; consider passing a pointer", i+1, call, t, t.Width)
+		lineno = lno
+	}
+}
+
+// havewant formats the argument types nl and the parameter list
+// tstruct of a call for an argument count error.
+func havewant(isddd bool, tstruct *Type, nl Nodes) string {
+	if !tstruct.Funarg {
+		return ""
+	}
+
+	var have []string
+	if nl.Len() == 1 && nl.First().Type != nil && nl.First().Type.Etype == TSTRUCT && nl.First().Type.Funarg {
+		for _, f := range nl.First().Type.Fields().Slice() {
+			have = append(have, f.Type.String())
+		}
+	} else {
+		for _, n := range nl.Slice() {
+			if n.Type == nil {
+				// Already reported.
+				return ""
+			}
+			have = append(have, n.Type.String())
+		}
+		if isddd && len(have) > 0 {
+			have[len(have)-1] += "..."
+		}
+	}
+
+	var want []string
+	for _, f := range tstruct.Fields().Slice() {
+		if f.Isddd {
+			want = append(want, "..."+f.Type.Type.String())
+		} else {
+			want = append(want, f.Type.String())
+		}
+	}
+
+	return fmt.Sprintf("\n\thave (%s)\n\twant (%s)", strings.Join(have, ", "), strings.Join(want, ", "))
+}
+
 // type check composite
-func fielddup(n *Node, hash map[string]bool) {
+func fielddup(n *Node, hash map[string]*Node) {
 	if n.Op != ONAME {
 		Fatalf("fielddup: not ONAME")
 	}
 	name := n.Sym.Name
-	if hash[name] {
-		Yyerror("duplicate field name in struct literal: %s", name)
+	if prev := hash[name]; prev != nil {
+		Yyerror("duplicate field name in struct literal: %s (previously set at %v)", name, prev.Line())
 		return
 	}
-	hash[name] = true
+	hash[name] = n
 }
 
-func keydup(n *Node, hash map[uint32][]*Node) {
+// keydup reports the key of the map literal element kv if an earlier
+// element stored in hash has the same constant key, and otherwise
+// adds kv to hash.
+func keydup(kv *Node, hash map[uint32][]*Node) {
+	n := kv.Left
 	orign := n
 	if n.Op == OCONVIFACE {
 		n = n.Left
@@ -2809,7 +3211,8 @@ func keydup(n *Node, hash map[uint32][]*Node) {
 	}
 
 	var cmp Node
-	for _, a := range hash[h] {
+	for _, prev := range hash[h] {
+		a := prev.Left
 		cmp.Op = OEQ
 		cmp.Left = n
 		if a.Op == OCONVIFACE && orign.Op == OCONVIFACE {
@@ -2825,25 +3228,29 @@ func keydup(n *Node, hash map[uint32][]*Node) {
 			continue
 		}
 		if cmp.Val().U.(bool) {
-			Yyerror("duplicate key %v in map literal", n)
+			Yyerror("duplicate key %v in map literal (previously set at %v)", n, prev.Line())
 			return
 		}
 	}
 
-	hash[h] = append(hash[h], orign)
+	hash[h] = append(hash[h], kv)
 }
 
-func indexdup(n *Node, hash map[int64]*Node) {
+// indexdup reports the index of the array literal element kv if an
+// earlier element stored in hash has the same index, and otherwise
+// adds kv to hash.
+func indexdup(kv *Node, hash map[int64]*Node) {
+	n := kv.Left
 	if n.Op != OLITERAL {
 		Fatalf("indexdup: not OLITERAL")
 	}
 
 	v := n.Val().U.(*Mpint).Int64()
-	if hash[v] != nil {
-		Yyerror("duplicate index in array literal: %d", v)
+	if prev := hash[v]; prev != nil {
+		Yyerror("duplicate index in array literal: %d (previously set at %v)", v, prev.Line())
 		return
 	}
-	hash[v] = n
+	hash[v] = kv
 }
 
 func iscomptype(t *Type) bool {
@@ -2921,15 +3328,17 @@ func typecheckcomplit(n *Node) *Node {
 		// except when using the &T syntax, which sets implicit on the OIND.
 		if !n.Right.Implicit {
 			Yyerror("invalid pointer type %v for composite literal (use &%v instead)", t, t.Type)
-			n.Type = nil
-			return n
-		}
-
-		// Also, the underlying type must be a struct, map, slice, or array.
-		if !iscomptype(t) {
+			if Debug_complit == 0 {
+				n.Type = nil
+				return n
+			}
+		} else if !iscomptype(t) {
+			// Also, the underlying type must be a struct, map, slice, or array.
 			Yyerror("invalid pointer type %v for composite literal", t)
-			n.Type = nil
-			return n
+			if Debug_complit == 0 {
+				n.Type = nil
+				return n
+			}
 		}
 
 		t = t.Type
@@ -2938,7 +3347,20 @@ func typecheckcomplit(n *Node) *Node {
 	var r *Node
 	switch t.Etype {
 	default:
-		Yyerror("invalid type for composite literal: %v", t)
+		// Under -d=complit a pointer error may already have
+		// brought us here; check the values for errors anyway.
+		if nerr == nerrors {
+			Yyerror("invalid type for composite literal: %v", t)
+		}
+		if Debug_complit != 0 {
+			for i, n1 := range n.List.Slice() {
+				if n1.Op == OKEY {
+					n1.Right = typecheck(n1.Right, Erv)
+				} else {
+					n.List.SetIndex(i, typecheck(n1, Erv))
+				}
+			}
+		}
 		n.Type = nil
 
 	case TARRAY:
@@ -2972,7 +3394,7 @@ func typecheckcomplit(n *Node) *Node {
 			}
 
 			if i >= 0 && hash != nil {
-				indexdup(l.Left, hash)
+				indexdup(l, hash)
 			}
 			i++
 			if int64(i) > length {
@@ -2993,6 +3415,8 @@ func typecheckcomplit(n *Node) *Node {
 
 		if t.isDDDArray() {
 			t.Bound = length
+		} else if hinting() && hash == nil && length > 0 && length < t.Bound {
+			Warnl(n.Lineno, "array literal has %d of %d elements; remaining are zero-valued", length, t.Bound)
 		}
 		if t.Bound < 0 {
 			n.Right = Nodintconst(length)
@@ -3017,7 +3441,7 @@ func typecheckcomplit(n *Node) *Node {
 			r = defaultlit(r, t.Key())
 			l.Left = assignconv(r, t.Key(), "map key")
 			if l.Left.Op != OCONV {
-				keydup(l.Left, hash)
+				keydup(l, hash)
 			}
 
 			r = l.Right
@@ -3035,6 +3459,13 @@ func typecheckcomplit(n *Node) *Node {
 
 		bad := 0
 		if n.List.Len() != 0 && nokeys(n.List) {
+			// Positional literals of imported structs break when
+			// the struct gains a field. Literals whose type is
+			// implied by an enclosing literal are not reported.
+			if hinting() && !n.Implicit && t.Sym != nil && t.Sym.Pkg != localpkg {
+				Warnl(n.Lineno, "%v literal uses unkeyed fields; use field names", t)
+			}
+
 			// simple list of variables
 			f, it := IterFields(t)
 
@@ -3070,7 +3501,7 @@ func typecheckcomplit(n *Node) *Node {
 				Yyerror("too few values in struct initializer")
 			}
 		} else {
-			hash := make(map[string]bool)
+			hash := make(map[string]*Node)
 
 			// keyed list
 			ls := n.List.Slice()
@@ -3092,6 +3523,12 @@ func typecheckcomplit(n *Node) *Node {
 					continue
 				}
 
+				if isblanksym(s) {
+					Yyerror("cannot use _ as a struct field name in literal")
+					l.Right = typecheck(l.Right, Erv)
+					continue
+				}
+
 				// Sym might have resolved to name in other top-level
 				// package, because of import dot. Redirect to correct sym
 				// before we do the lookup.
@@ -3210,12 +3647,31 @@ func checkassign(stmt *Node, n *Node) {
 		return
 	}
 
+	if n.Op == OLITERAL && n.Sym != nil {
+		Yyerror("cannot assign to constant %v", n)
+		return
+	}
+
+	// Map elements are not addressable, so neither are their fields.
+	if n.Op == ODOT && outervalue(n).Op == OINDEXMAP {
+		Yyerror("cannot assign to struct field %v in map (assign the whole map element instead)", n)
+		return
+	}
+
 	Yyerror("cannot assign to %v", n)
 }
 
 func checkassignlist(stmt *Node, l Nodes) {
-	for _, n := range l.Slice() {
+	for i, n := range l.Slice() {
 		checkassign(stmt, n)
+		if hinting() && !isblank(n) && n.Type != nil {
+			for _, m := range l.Slice()[:i] {
+				if m.Type != nil && samesafeexpr(m, n) {
+					Warnl(stmt.Lineno, "%v assigned multiple times in assignment", n)
+					break
+				}
+			}
+		}
 	}
 }
 
@@ -3263,7 +3719,7 @@ func typecheckas(n *Node) {
 	n.Right = typecheck(n.Right, Erv)
 	checkassign(n, n.Left)
 	if n.Right != nil && n.Right.Type != nil {
-		if n.Left.Type != nil {
+		if n.Left.Type != nil && n.Left.Op != OLITERAL {
 			n.Right = assignconv(n.Right, n.Left.Type, "assignment")
 		}
 	}
@@ -3281,6 +3737,44 @@ func typecheckas(n *Node) {
 	if n.Left.Typecheck == 0 {
 		n.Left = typecheck(n.Left, Erv|Easgn)
 	}
+	checkselfassign(n, n.Left, n.Right)
+}
+
+// cmpoverflow returns the untyped integer constant operand of a
+// comparison between l and r, and the integer type of the other
+// operand, if the constant does not fit in that type.
+func cmpoverflow(l, r *Node) (*Node, *Type) {
+	if l.Type == nil || r.Type == nil {
+		return nil, nil
+	}
+	for _, c := range [2][2]*Node{{l, r}, {r, l}} {
+		k, x := c[0], c[1]
+		if k.Op != OLITERAL || k.Type.Etype != TIDEAL || !Isint[x.Type.Etype] {
+			continue
+		}
+		switch k.Val().Ctype() {
+		case CTINT, CTRUNE:
+			if doesoverflow(k.Val(), x.Type) {
+				return k, x.Type
+			}
+		}
+	}
+	return nil, nil
+}
+
+// checkselfassign reports the assignment stmt of r to l under -d hint
+// if both are the same side effect-free variable, field, index,
+// or indirection expression.
+func checkselfassign(stmt *Node, l *Node, r *Node) {
+	if !hinting() || l == nil || r == nil || l.Type == nil || isblank(l) || !IsPure(r) {
+		return
+	}
+	switch l.Op {
+	case ONAME, ODOT, ODOTPTR, OINDEX, OIND:
+		if samesafeexpr(l, r) {
+			Warnl(stmt.Lineno, "self-assignment of %v to %v", r, l)
+		}
+	}
 }
 
 func checkassignto(src *Type, dst *Node) {
@@ -3321,13 +3815,14 @@ func typecheckas2(n *Node) {
 		rs := n.Rlist.Slice()
 		for il, nl := range ls {
 			nr := rs[il]
-			if nl.Type != nil && nr.Type != nil {
+			if nl.Type != nil && nr.Type != nil && nl.Op != OLITERAL {
 				rs[il] = assignconv(nr, nl.Type, "assignment")
 			}
 			if nl.Name != nil && nl.Name.Defn == n && nl.Name.Param.Ntype == nil {
 				rs[il] = defaultlit(rs[il], nil)
 				nl.Type = rs[il].Type
 			}
+			checkselfassign(n, nl, rs[il])
 		}
 
 		goto out
@@ -3403,6 +3898,10 @@ func typecheckas2(n *Node) {
 	}
 
 mismatch:
+	if cl > 2 && cr == 1 && n.Rlist.First().Op == OINDEXMAP {
+		Yyerror("assignment count mismatch: %d = %d: map index returns at most 2 values (value, ok)", cl, cr)
+		goto out
+	}
 	Yyerror("assignment count mismatch: %d = %d", cl, cr)
 
 	// second half of dance
@@ -3503,6 +4002,30 @@ func domethod(n *Node) {
 
 var mapqueue []*Node
 
+// largemapqueue holds map type expressions whose value width
+// is checked by checkmapvalues once all types have settled.
+var largemapqueue []*Node
+
+// largeMapValue is the value width in bytes above which -d hint
+// suggests storing pointers in a map instead.
+const largeMapValue = 128
+
+// checkmapvalues reports map types in largemapqueue whose
+// value type is larger than largeMapValue.
+func checkmapvalues() {
+	for _, n := range largemapqueue {
+		t := n.Type
+		if t == nil || t.Etype != TMAP || t.Val().Broke {
+			continue
+		}
+		dowidth(t.Val())
+		if w := t.Val().Width; w > largeMapValue {
+			Warnl(n.Lineno, "map value type %v is large (%d bytes); consider map[%v]*%v", t.Val(), w, t.Key(), t.Val())
+		}
+	}
+	largemapqueue = nil
+}
+
 func copytype(n *Node, t *Type) {
 	if t.Etype == TFORW {
 		// This type isn't computed yet; when it is, update n.
@@ -3617,6 +4140,29 @@ func queuemethod(n *Node) {
 	methodqueue = append(methodqueue, n)
 }
 
+// constiota returns the value of iota used by the const
+// initializer n, or -1 if n does not refer to iota.
+func constiota(n *Node) int32 {
+	if n == nil {
+		return -1
+	}
+	if n.Op == ONONAME && n.Name != nil && n.Name.Iota >= 0 && n.Sym != nil && n.Sym.Def != nil && n.Sym.Def.Op == OIOTA {
+		return n.Name.Iota
+	}
+	if i := constiota(n.Left); i >= 0 {
+		return i
+	}
+	if i := constiota(n.Right); i >= 0 {
+		return i
+	}
+	for _, n1 := range n.List.Slice() {
+		if i := constiota(n1); i >= 0 {
+			return i
+		}
+	}
+	return -1
+}
+
 func typecheckdef(n *Node) *Node {
 	lno := lineno
 	setlineno(n)
@@ -3685,9 +4231,14 @@ func typecheckdef(n *Node) *Node {
 			Yyerror("xxx")
 		}
 
+		iotaval := constiota(e)
 		e = typecheck(e, Erv|Eiota)
 		if Isconst(e, CTNIL) {
-			Yyerror("const initializer cannot be nil")
+			if t := n.Type; t != nil && (t.IsPtr() || t.IsSlice() || t.IsInterface()) {
+				Yyerror("const initializer cannot be nil (use a var declaration; constants cannot be nil)")
+			} else {
+				Yyerror("const initializer cannot be nil")
+			}
 			goto ret
 		}
 
@@ -3712,6 +4263,11 @@ func typecheckdef(n *Node) *Node {
 				goto ret
 			}
 
+			if iotaval >= 0 && Isint[t.Etype] && (e.Val().Ctype() == CTINT || e.Val().Ctype() == CTRUNE) && doesoverflow(e.Val(), t) {
+				Yyerror("constant %s (iota=%d) overflows %v", Vconv(e.Val(), 0), iotaval, t)
+				goto ret
+			}
+
 			e = convlit(e, t)
 		}
 
@@ -3805,7 +4361,7 @@ func checkmake(t *Type, arg string, n *Node) bool {
 			}
 
 			if n.Val().U.(*Mpint).Cmp(Maxintval[TINT]) > 0 {
-				Yyerror("%s argument too large in make(%v)", arg, t)
+				Yyerror("%s argument %v exceeds maximum int value %v in make(%v)", arg, n.Val().U.(*Mpint), Maxintval[TINT], t)
 				return false
 			}
 
@@ -3962,4 +4518,43 @@ func checkreturn(fn *Node) {
 			yyerrorl(fn.Func.Endlineno, "missing return at end of function")
 		}
 	}
+	if hinting() && nerrors == 0 {
+		markbreaklist(fn.Nbody, nil)
+		checkunreachable(fn.Nbody)
+	}
+}
+
+// checkunreachable notes the first statement in each block of l
+// that follows a terminating statement and so can never be executed.
+// Labeled statements are skipped, since they may be reached by goto.
+func checkunreachable(l Nodes) {
+	s := l.Slice()
+	for i, n := range s {
+		if i+1 < len(s) && s[i+1].Op != OLABEL && n.isterminating() {
+			switch n.Op {
+			case ORETURN, ORETJMP:
+				Warnl(s[i+1].Lineno, "unreachable code after return")
+			case OGOTO:
+				Warnl(s[i+1].Lineno, "unreachable code after goto")
+			case OPANIC:
+				Warnl(s[i+1].Lineno, "unreachable code after panic")
+			case OFOR:
+				Warnl(s[i+1].Lineno, "unreachable code after infinite loop")
+			default:
+				Warnl(s[i+1].Lineno, "unreachable code")
+			}
+		}
+
+		switch n.Op {
+		case OBLOCK:
+			checkunreachable(n.List)
+
+		case OSWITCH, OTYPESW, OSELECT:
+			for _, n1 := range n.List.Slice() {
+				checkunreachable(n1.Nbody)
+			}
+		}
+		checkunreachable(n.Nbody)
+		checkunreachable(n.Rlist)
+	}
 }
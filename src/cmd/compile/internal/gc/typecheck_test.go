@@ -0,0 +1,79 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gc
+
+import (
+	"cmd/internal/obj"
+	"testing"
+)
+
+func TestIsPure(t *testing.T) {
+	a := Nod(ONAME, nil, nil)
+	b := Nod(ONAME, nil, nil)
+	f := Nod(ONAME, nil, nil)
+	ch := Nod(ONAME, nil, nil)
+
+	tests := []struct {
+		name string
+		n    *Node
+		want bool
+	}{
+		{"a+b", Nod(OADD, a, b), true},
+		{"-a", Nod(OMINUS, a, nil), true},
+		{"f()", Nod(OCALL, f, nil), false},
+		{"<-ch", Nod(ORECV, ch, nil), false},
+		{"a+<-ch", Nod(OADD, a, Nod(ORECV, ch, nil)), false},
+		{"ch <- a", Nod(OSEND, ch, a), false},
+		{"a = b", Nod(OAS, a, b), false},
+	}
+	for _, tt := range tests {
+		if got := IsPure(tt.n); got != tt.want {
+			t.Errorf("IsPure(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+type diagRecorder []Diagnostic
+
+func (r *diagRecorder) Diagnose(d Diagnostic) {
+	*r = append(*r, d)
+}
+
+func TestDiagsink(t *testing.T) {
+	defer func(ctxt *obj.Link, n int, sink DiagnosticSink) {
+		Ctxt, nerrors, Diagsink = ctxt, n, sink
+		errors = errors[:0]
+	}(Ctxt, nerrors, Diagsink)
+	if Ctxt == nil {
+		Ctxt = new(obj.Link)
+	}
+	var r diagRecorder
+	Diagsink = &r
+
+	Yyerror("outside typecheck")
+	typecheck_tcstack = append(typecheck_tcstack, Nod(OINDEX, nil, nil))
+	Yyerror("invalid %s", "index")
+	yyerrorl(42, "at line %d", 42)
+	Warnl(7, "hint")
+	typecheck_tcstack = typecheck_tcstack[:0]
+
+	want := []Diagnostic{
+		{lineno, SeverityError, "", "outside typecheck"},
+		{lineno, SeverityError, "index", "invalid index"},
+		{42, SeverityError, "index", "at line 42"},
+		{7, SeverityWarning, "index", "hint"},
+	}
+	if len(r) != len(want) {
+		t.Fatalf("recorded %d diagnostics, want %d: %v", len(r), len(want), r)
+	}
+	for i := range want {
+		if r[i] != want[i] {
+			t.Errorf("diagnostic %d = %+v, want %+v", i, r[i], want[i])
+		}
+	}
+	if len(errors) != len(want) {
+		t.Errorf("text output has %d messages, want %d", len(errors), len(want))
+	}
+}
@@ -990,7 +990,7 @@ opswitch:
 		// Optimize convT2E or convT2I as a two-word copy when T is pointer-shaped.
 		if isdirectiface(n.Left.Type) {
 			var t *Node
-			if isnilinter(n.Type) {
+			if n.Type.IsEmptyInterface() {
 				t = typename(n.Left.Type)
 			} else {
 				t = itabname(n.Left.Type, n.Type)
@@ -1003,7 +1003,7 @@ opswitch:
 		}
 
 		var ll []*Node
-		if isnilinter(n.Type) {
+		if n.Type.IsEmptyInterface() {
 			if !Isinter(n.Left.Type) {
 				ll = append(ll, typename(n.Left.Type))
 			}
@@ -1504,7 +1504,7 @@ opswitch:
 			Fatalf("ifaceeq %v %v %v", Oconv(n.Op, 0), n.Left.Type, n.Right.Type)
 		}
 		var fn *Node
-		if isnilinter(n.Left.Type) {
+		if n.Left.Type.IsEmptyInterface() {
 			fn = syslook("efaceeq")
 		} else {
 			fn = syslook("ifaceeq")
@@ -1926,7 +1926,7 @@ func walkprint(nn *Node, init *Nodes) *Node {
 		t = n.Type
 		et = n.Type.Etype
 		if Isinter(n.Type) {
-			if isnilinter(n.Type) {
+			if n.Type.IsEmptyInterface() {
 				on = syslook("printeface")
 			} else {
 				on = syslook("printiface")
@@ -323,6 +323,14 @@ func relocsym(s *LSym) {
 			// these symbols are undefined and that's OK.
 			if Buildmode == BuildmodeShared && (r.Sym.Name == "main.main" || r.Sym.Name == "main.init") {
 				r.Sym.Type = obj.SDYNIMPORT
+			} else if r.Weak {
+				// An undefined weak reference resolves to nil.
+				// Leave r.Sym alone: other references to it
+				// may be strong and must still be reported.
+				for i := off; i < off+siz; i++ {
+					s.P[i] = 0
+				}
+				continue
 			} else {
 				Diag("%s: not defined", r.Sym.Name)
 				continue
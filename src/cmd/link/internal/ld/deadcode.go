@@ -338,6 +338,9 @@ func (d *deadcodepass) flood() {
 				d.mark(s.Pcln.Funcdata[i], s)
 			}
 		}
+		for _, k := range s.Keep {
+			d.mark(k, s)
+		}
 		d.mark(s.Gotype, s)
 		d.mark(s.Sub, s)
 		d.mark(s.Outer, s)
@@ -523,6 +523,7 @@ func loadlib() {
 			objfile(Ctxt.Library[i])
 		}
 	}
+	Ctxt.appendTextsects()
 
 	for i = 0; i < len(Ctxt.Library); i++ {
 		if Ctxt.Library[i].Shlib != "" {
@@ -2061,7 +2062,7 @@ func undefsym(s *LSym) {
 		if r.Sym == nil { // happens for some external ARM relocs
 			continue
 		}
-		if r.Sym.Type == obj.Sxxx || r.Sym.Type == obj.SXREF {
+		if (r.Sym.Type == obj.Sxxx || r.Sym.Type == obj.SXREF) && !r.Weak {
 			Diag("undefined: %s", r.Sym.Name)
 		}
 		if !r.Sym.Attr.Reachable() {
@@ -52,6 +52,7 @@ type LSym struct {
 	LocalElfsym int32
 	Args        int32
 	Locals      int32
+	Order       int32 // layout priority of a function, if recorded
 	Value       int64
 	Size        int64
 	// ElfType is set for symbols read from shared libraries by ldshlibsyms. It
@@ -69,6 +70,8 @@ type LSym struct {
 	Sect        *Section
 	Autom       []Auto
 	Pcln        *Pcln
+	Clobbers    []byte  // register-clobber bitmap of a function, if recorded
+	Keep        []*LSym // symbols kept alive by this one, if recorded
 	P           []byte
 	R           []Reloc
 }
@@ -106,6 +109,8 @@ const (
 	AttrOnList
 	AttrLocal
 	AttrReflectMethod
+	AttrExported
+	AttrReadOnly
 )
 
 func (a Attribute) DuplicateOK() bool      { return a&AttrDuplicateOK != 0 }
@@ -120,6 +125,8 @@ func (a Attribute) Hidden() bool           { return a&AttrHidden != 0 }
 func (a Attribute) OnList() bool           { return a&AttrOnList != 0 }
 func (a Attribute) Local() bool            { return a&AttrLocal != 0 }
 func (a Attribute) ReflectMethod() bool    { return a&AttrReflectMethod != 0 }
+func (a Attribute) Exported() bool         { return a&AttrExported != 0 }
+func (a Attribute) ReadOnly() bool         { return a&AttrReadOnly != 0 }
 
 func (a Attribute) CgoExport() bool {
 	return a.CgoExportDynamic() || a.CgoExportStatic()
@@ -143,6 +150,7 @@ type Reloc struct {
 	Xadd    int64
 	Sym     *LSym
 	Xsym    *LSym
+	Weak    bool // Sym may be left undefined; it then resolves to nil
 }
 
 type Auto struct {
@@ -189,7 +197,67 @@ type Link struct {
 	Filesyms   *LSym
 	Moduledata *LSym
 	LSymBatch  []LSym
-	CurRefs    []*LSym // List of symbol references for the file being read.
+	CurRefs    []*LSym      // List of symbol references for the file being read.
+	CurWeak    map[int]bool // Indexes of the weak references in CurRefs.
+	CurVersion int          // Format version of the file being read.
+
+	// refCache caches symbol reference lookups
+	// for the file being read. See lookupRef.
+	refCache map[symRef]*LSym
+
+	// RemapFile, if non-nil, rewrites the source file paths
+	// recorded in the pcln tables of the object files being read.
+	RemapFile func(path string) string
+
+	// Textsects holds the functions placed in named text sections,
+	// in order of the sections' first use. See appendTextsects.
+	Textsects []*Textsect
+}
+
+// A Textsect is a named text section and the list of
+// functions read into it.
+type Textsect struct {
+	Name   string
+	Textp  *LSym
+	Etextp *LSym
+}
+
+func (sect *Textsect) add(s *LSym) {
+	if sect.Etextp != nil {
+		sect.Etextp.Next = s
+	} else {
+		sect.Textp = s
+	}
+	sect.Etextp = s
+}
+
+// textsect returns the named text section, creating it if needed.
+func (ctxt *Link) textsect(name string) *Textsect {
+	for _, sect := range ctxt.Textsects {
+		if sect.Name == name {
+			return sect
+		}
+	}
+	sect := &Textsect{Name: name}
+	ctxt.Textsects = append(ctxt.Textsects, sect)
+	return sect
+}
+
+// appendTextsects moves the functions of the named text sections
+// to the end of ctxt.Textp, keeping each section's functions together.
+func (ctxt *Link) appendTextsects() {
+	for _, sect := range ctxt.Textsects {
+		if sect.Textp == nil {
+			continue
+		}
+		if ctxt.Etextp != nil {
+			ctxt.Etextp.Next = sect.Textp
+		} else {
+			ctxt.Textp = sect.Textp
+		}
+		ctxt.Etextp = sect.Etextp
+	}
+	ctxt.Textsects = nil
 }
 
 // The smallest possible offset from the hardware stack pointer to a local
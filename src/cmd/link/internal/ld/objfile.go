@@ -18,7 +18,7 @@ package ld
 // The file format is:
 //
 //	- magic header: "\x00\x00go13ld"
-//	- byte 1 - version number
+//	- byte 1 through 10 - version number
 //	- sequence of strings giving dependencies (imported packages)
 //	- empty string (marks end of sequence)
 //	- sequence of sybol references used by the defined symbols
@@ -35,7 +35,10 @@ package ld
 // Data blocks and strings are both stored as an integer
 // followed by that many bytes.
 //
-// A symbol reference is a string name followed by a version.
+// A symbol reference is a string name followed by a version and,
+// in version 5 and later, reference flags [int]:
+//
+//	1<<0 weak: an undefined symbol resolves to nil
 //
 // A symbol points to other symbols using an index into the symbol
 // reference sequence. Index 0 corresponds to a nil LSym* pointer.
@@ -48,10 +51,22 @@ package ld
 //	- type [int]
 //	- name & version [symref index]
 //	- flags [int]
-//		1 dupok
+//		1<<0 dupok
+//		1<<1 local
+//		1<<2 visibility follows (version 2 and later)
+//		1<<3 read-only (version 3 and later)
+//		1<<4 data length follows (version 4 and later)
+//		1<<5 keep-alive edges follow (version 9 and later)
+//	- visibility [int], if flags&(1<<2) != 0
+//		1 hidden
+//		2 exported
 //	- size [int]
 //	- gotype [symref index]
 //	- p [data block]
+//	- datalen [int], the length of p, if flags&(1<<4) != 0
+//	- nkeep [int], if flags&(1<<5) != 0
+//	- keep [nkeep symref index], symbols that must be kept
+//	  if this one is reachable, if flags&(1<<5) != 0
 //	- nr [int]
 //	- r [nr relocations, sorted by off]
 //
@@ -64,9 +79,16 @@ package ld
 //		1<<0 leaf
 //		1<<1 C function
 //		1<<2 function may call reflect.Type.Method
+//		1<<3 register-clobber bitmap follows (version 6 and later)
+//		1<<4 ordering hint follows (version 7 and later)
+//		1<<5 pcsp, pcfile, and pcline are flate-compressed (version 8 and later)
+//		1<<6 text section name follows (version 10 and later)
 //	- nlocal [int]
 //	- local [nlocal automatics]
 //	- pcln [pcln table]
+//	- clobbers [data block], if flags&(1<<3) != 0
+//	- order [int], if flags&(1<<4) != 0
+//	- section [string], if flags&(1<<6) != 0; "" is the default section
 //
 // Each relocation has the encoding:
 //
@@ -88,6 +110,7 @@ package ld
 //	- pcsp [data block]
 //	- pcfile [data block]
 //	- pcline [data block]
+//	  (each flate-compressed if the function flags have 1<<5 set)
 //	- npcdata [int]
 //	- pcdata [npcdata data blocks]
 //	- nfuncdata [int]
@@ -104,6 +127,10 @@ package ld
 import (
 	"bytes"
 	"cmd/internal/obj"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"log"
 	"strconv"
 	"strings"
@@ -112,9 +139,32 @@ import (
 const (
 	startmagic = "\x00\x00go13ld"
 	endmagic   = "\xff\xffgo13ld"
+	maxversion = 10
 )
 
+// symflags gives the symbol flag bits defined by each file version.
+var symflags = [maxversion + 1]int{
+	1:  1<<0 | 1<<1,
+	2:  1<<0 | 1<<1 | 1<<2,
+	3:  1<<0 | 1<<1 | 1<<2 | 1<<3,
+	4:  1<<0 | 1<<1 | 1<<2 | 1<<3 | 1<<4,
+	5:  1<<0 | 1<<1 | 1<<2 | 1<<3 | 1<<4,
+	6:  1<<0 | 1<<1 | 1<<2 | 1<<3 | 1<<4,
+	7:  1<<0 | 1<<1 | 1<<2 | 1<<3 | 1<<4,
+	8:  1<<0 | 1<<1 | 1<<2 | 1<<3 | 1<<4,
+	9:  1<<0 | 1<<1 | 1<<2 | 1<<3 | 1<<4 | 1<<5,
+	10: 1<<0 | 1<<1 | 1<<2 | 1<<3 | 1<<4 | 1<<5,
+}
+
 func ldobjfile(ctxt *Link, f *obj.Biobuf, pkg string, length int64, pn string) {
+	defer func() {
+		if e := recover(); e != nil {
+			if err, ok := e.(objError); ok {
+				log.Fatal(err)
+			}
+			panic(e)
+		}
+	}()
 	start := obj.Boffset(f)
 	ctxt.IncVersion()
 	var buf [8]uint8
@@ -123,9 +173,10 @@ func ldobjfile(ctxt *Link, f *obj.Biobuf, pkg string, length int64, pn string) {
 		log.Fatalf("%s: invalid file start %x %x %x %x %x %x %x %x", pn, buf[0], buf[1], buf[2], buf[3], buf[4], buf[5], buf[6], buf[7])
 	}
 	c := obj.Bgetc(f)
-	if c != 1 {
+	if c < 1 || c > maxversion {
 		log.Fatalf("%s: invalid file version number %d", pn, c)
 	}
+	ctxt.CurVersion = c
 
 	var lib string
 	for {
@@ -137,6 +188,8 @@ func ldobjfile(ctxt *Link, f *obj.Biobuf, pkg string, length int64, pn string) {
 	}
 
 	ctxt.CurRefs = []*LSym{nil} // zeroth ref is nil
+	ctxt.CurWeak = nil
+	ctxt.refCache = nil
 	for {
 		c, err := f.Peek(1)
 		if err != nil {
@@ -148,6 +201,7 @@ func ldobjfile(ctxt *Link, f *obj.Biobuf, pkg string, length int64, pn string) {
 		}
 		readref(ctxt, f, pkg, pn)
 	}
+	ctxt.refCache = nil
 
 	dataLength := rdint64(f)
 	data := make([]byte, dataLength)
@@ -175,20 +229,207 @@ func ldobjfile(ctxt *Link, f *obj.Biobuf, pkg string, length int64, pn string) {
 	}
 }
 
+// LoadSymbol reads the Go object file in f and returns the symbol
+// with the given name, decoding only that symbol. The other symbols
+// are skipped, but the symbol references and data they share are
+// still read. Names are matched as they appear in the object file,
+// with "". standing for the package being compiled.
+func LoadSymbol(f *obj.Biobuf, name string) (s *LSym, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			objerr, ok := e.(objError)
+			if !ok {
+				panic(e)
+			}
+			// readsym prefixes some messages with the
+			// file name, which LoadSymbol does not know.
+			s, err = nil, errors.New(strings.TrimPrefix(string(objerr), ": "))
+		}
+	}()
+	const pkg = `""`
+	ctxt := &Link{Hash: []map[string]*LSym{make(map[string]*LSym)}}
+	ctxt.IncVersion()
+	var buf [8]uint8
+	obj.Bread(f, buf[:])
+	if string(buf[:]) != startmagic {
+		return nil, fmt.Errorf("invalid file start %x", buf[:])
+	}
+	c := obj.Bgetc(f)
+	if c < 1 || c > maxversion {
+		return nil, fmt.Errorf("invalid file version number %d", c)
+	}
+	ctxt.CurVersion = c
+
+	for rdstring(f) != "" {
+		// skip dependencies
+	}
+
+	ctxt.CurRefs = []*LSym{nil} // zeroth ref is nil
+	ctxt.CurWeak = nil
+	for {
+		c, err := f.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if c[0] == 0xff {
+			obj.Bgetc(f)
+			break
+		}
+		if obj.Bgetc(f) != 0xfe {
+			return nil, fmt.Errorf("symbol reference out of sync")
+		}
+		name := rdsymName(f, pkg)
+		v := rdint(f)
+		if v == 1 {
+			v = ctxt.Version
+		}
+		ctxt.CurRefs = append(ctxt.CurRefs, Linklookup(ctxt, name, v))
+		if ctxt.CurVersion >= 5 && rdint(f)&1 != 0 {
+			addweakref(ctxt)
+		}
+	}
+
+	dataLength := rdint64(f)
+	if dataLength < 0 {
+		return nil, fmt.Errorf("invalid data length %d", dataLength)
+	}
+	data := make([]byte, dataLength)
+	obj.Bread(f, data)
+
+	for {
+		c, err := f.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if c[0] == 0xff {
+			break
+		}
+		off, rest := obj.Boffset(f), data
+		s := skipsym(ctxt, f, &data, pkg)
+		if s == nil || s.Name != name {
+			continue
+		}
+		obj.Bseek(f, off, 0)
+		data = rest
+		readsym(ctxt, f, &data, pkg, "")
+		return s, nil
+	}
+	return nil, fmt.Errorf("symbol %s not found", name)
+}
+
 var dupSym = &LSym{Name: ".dup"}
 
+// skipsym consumes the symbol at the current position in f without
+// defining it, and returns the symbol it defines.
+func skipsym(ctxt *Link, f *obj.Biobuf, buf *[]byte, pkg string) *LSym {
+	if obj.Bgetc(f) != 0xfe {
+		objFatalf("readsym out of sync")
+	}
+	t := rdint(f)
+	s := rdsym(ctxt, f, pkg)
+	flags := rdint(f)
+	if flags&4 != 0 {
+		rdint(f) // visibility
+	}
+	rdint(f)            // size
+	rdsym(ctxt, f, pkg) // gotype
+	rddata(f, buf)
+	if flags&16 != 0 {
+		rdint(f) // datalen
+	}
+	if flags&32 != 0 {
+		n := rdint(f)
+		for i := 0; i < n; i++ {
+			rdsym(ctxt, f, pkg) // keep
+		}
+	}
+	nreloc := rdint(f)
+	for i := 0; i < nreloc; i++ {
+		rdint32(f) // off
+		rduint8(f) // siz
+		rdint32(f) // type
+		rdint64(f) // add
+		rdsym(ctxt, f, pkg)
+	}
+
+	if t == obj.STEXT {
+		rdint32(f) // args
+		rdint32(f) // locals
+		rduint8(f) // nosplit
+		flags := rdint(f)
+		n := rdint(f)
+		for i := 0; i < n; i++ {
+			rdsym(ctxt, f, pkg)
+			rdint32(f)
+			rdint16(f)
+			rdsym(ctxt, f, pkg)
+		}
+		for i := 0; i < 3; i++ {
+			rddata(f, buf) // pcsp, pcfile, pcline
+		}
+		n = rdint(f)
+		for i := 0; i < n; i++ {
+			rddata(f, buf)
+		}
+		n = rdint(f)
+		for i := 0; i < n; i++ {
+			rdsym(ctxt, f, pkg)
+		}
+		for i := 0; i < n; i++ {
+			rdint64(f)
+		}
+		n = rdint(f)
+		for i := 0; i < n; i++ {
+			rdsym(ctxt, f, pkg)
+		}
+		if flags&(1<<3) != 0 {
+			rddata(f, buf) // clobbers
+		}
+		if flags&(1<<4) != 0 {
+			rdint(f) // order
+		}
+		if flags&(1<<6) != 0 {
+			rdstring(f) // section
+		}
+	}
+	return s
+}
+
 func readsym(ctxt *Link, f *obj.Biobuf, buf *[]byte, pkg string, pn string) {
 	if obj.Bgetc(f) != 0xfe {
-		log.Fatalf("readsym out of sync")
+		objFatalf("readsym out of sync")
 	}
 	t := rdint(f)
 	s := rdsym(ctxt, f, pkg)
 	flags := rdint(f)
+	if flags&^symflags[ctxt.CurVersion] != 0 {
+		objFatalf("%s: invalid flags %#x for %s in version %d object file", pn, flags, s.Name, ctxt.CurVersion)
+	}
 	dupok := flags&1 != 0
 	local := flags&2 != 0
+	readonly := flags&8 != 0
+	visibility := 0
+	if flags&4 != 0 {
+		visibility = rdint(f)
+		if visibility != 1 && visibility != 2 {
+			objFatalf("%s: invalid visibility %d for %s", pn, visibility, s.Name)
+		}
+	}
 	size := rdint(f)
 	typ := rdsym(ctxt, f, pkg)
 	data := rddata(f, buf)
+	if flags&16 != 0 {
+		if n := rdint(f); n != len(data) {
+			objFatalf("%s: symbol %s data length mismatch: declared %d, got %d", pn, s.Name, n, len(data))
+		}
+	}
+	var keep []*LSym
+	if flags&32 != 0 {
+		keep = make([]*LSym, rdint(f))
+		for i := range keep {
+			keep[i] = rdsym(ctxt, f, pkg)
+		}
+	}
 	nreloc := rdint(f)
 
 	var dup *LSym
@@ -200,6 +441,7 @@ func readsym(ctxt *Link, f *obj.Biobuf, buf *[]byte, pkg string, pn string) {
 			if typ != nil && s.Gotype == nil {
 				s.Gotype = typ
 			}
+			s.Keep = append(s.Keep, keep...)
 			return
 		}
 
@@ -207,7 +449,7 @@ func readsym(ctxt *Link, f *obj.Biobuf, buf *[]byte, pkg string, pn string) {
 			goto overwrite
 		}
 		if s.Type != obj.SBSS && s.Type != obj.SNOPTRBSS && !dupok && !s.Attr.DuplicateOK() {
-			log.Fatalf("duplicate symbol %s (types %d and %d) in %s and %s", s.Name, s.Type, t, s.File, pn)
+			objFatalf("duplicate symbol %s (types %d and %d) in %s and %s", s.Name, s.Type, t, s.File, pn)
 		}
 		if len(s.P) > 0 {
 			dup = s
@@ -220,11 +462,14 @@ overwrite:
 	if dupok {
 		s.Attr |= AttrDuplicateOK
 	}
+	if readonly {
+		s.Attr |= AttrReadOnly
+	}
 	if t == obj.SXREF {
-		log.Fatalf("bad sxref")
+		objFatalf("bad sxref")
 	}
 	if t == 0 {
-		log.Fatalf("missing type for %s in %s", s.Name, pn)
+		objFatalf("missing type for %s in %s", s.Name, pn)
 	}
 	if t == obj.SBSS && (s.Type == obj.SRODATA || s.Type == obj.SNOPTRBSS) {
 		t = int(s.Type)
@@ -234,6 +479,12 @@ overwrite:
 		s.Size = int64(size)
 	}
 	s.Attr.Set(AttrLocal, local)
+	switch visibility {
+	case 1:
+		s.Attr |= AttrHidden
+	case 2:
+		s.Attr |= AttrExported
+	}
 	if typ != nil {
 		s.Gotype = typ
 	}
@@ -241,6 +492,7 @@ overwrite:
 		dup.Gotype = typ
 	}
 	s.P = data
+	s.Keep = keep
 	if nreloc > 0 {
 		s.R = make([]Reloc, nreloc)
 		var r *Reloc
@@ -250,7 +502,9 @@ overwrite:
 			r.Siz = rduint8(f)
 			r.Type = rdint32(f)
 			r.Add = rdint64(f)
-			r.Sym = rdsym(ctxt, f, pkg)
+			j := rdint(f)
+			r.Sym = ctxt.CurRefs[j]
+			r.Weak = ctxt.CurWeak[j]
 		}
 	}
 
@@ -264,6 +518,18 @@ overwrite:
 		if flags&(1<<2) != 0 {
 			s.Attr |= AttrReflectMethod
 		}
+		if flags&(1<<3) != 0 && ctxt.CurVersion < 6 {
+			objFatalf("%s: register-clobber bitmap for %s in version %d object file", pn, s.Name, ctxt.CurVersion)
+		}
+		if flags&(1<<4) != 0 && ctxt.CurVersion < 7 {
+			objFatalf("%s: ordering hint for %s in version %d object file", pn, s.Name, ctxt.CurVersion)
+		}
+		if flags&(1<<5) != 0 && ctxt.CurVersion < 8 {
+			objFatalf("%s: compressed pcln tables for %s in version %d object file", pn, s.Name, ctxt.CurVersion)
+		}
+		if flags&(1<<6) != 0 && ctxt.CurVersion < 10 {
+			objFatalf("%s: text section name for %s in version %d object file", pn, s.Name, ctxt.CurVersion)
+		}
 		n := rdint(f)
 		s.Autom = make([]Auto, n)
 		for i := 0; i < n; i++ {
@@ -280,6 +546,15 @@ overwrite:
 		pc.Pcsp.P = rddata(f, buf)
 		pc.Pcfile.P = rddata(f, buf)
 		pc.Pcline.P = rddata(f, buf)
+		if flags&(1<<5) != 0 {
+			for _, p := range []*Pcdata{&pc.Pcsp, &pc.Pcfile, &pc.Pcline} {
+				data, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(p.P)))
+				if err != nil {
+					objFatalf("%s: corrupt pcln table for %s: %v", pn, s.Name, err)
+				}
+				p.P = data
+			}
+		}
 		n = rdint(f)
 		pc.Pcdata = make([]Pcdata, n)
 		for i := 0; i < n; i++ {
@@ -297,20 +572,39 @@ overwrite:
 		n = rdint(f)
 		pc.File = make([]*LSym, n)
 		for i := 0; i < n; i++ {
-			pc.File[i] = rdsym(ctxt, f, pkg)
+			file := rdsym(ctxt, f, pkg)
+			if ctxt.RemapFile != nil && file != nil {
+				if name := ctxt.RemapFile(file.Name); name != file.Name {
+					file = Linklookup(ctxt, name, int(file.Version))
+				}
+			}
+			pc.File[i] = file
+		}
+		if flags&(1<<3) != 0 {
+			s.Clobbers = rddata(f, buf)
+		}
+		if flags&(1<<4) != 0 {
+			s.Order = rdint32(f)
+		}
+		sect := ""
+		if flags&(1<<6) != 0 {
+			sect = rdstring(f)
 		}
 
 		if dup == nil {
 			if s.Attr.OnList() {
-				log.Fatalf("symbol %s listed multiple times", s.Name)
+				objFatalf("symbol %s listed multiple times", s.Name)
 			}
 			s.Attr |= AttrOnList
-			if ctxt.Etextp != nil {
+			if sect != "" {
+				ctxt.textsect(sect).add(s)
+			} else if ctxt.Etextp != nil {
 				ctxt.Etextp.Next = s
+				ctxt.Etextp = s
 			} else {
 				ctxt.Textp = s
+				ctxt.Etextp = s
 			}
-			ctxt.Etextp = s
 		}
 	}
 }
@@ -327,9 +621,19 @@ func readref(ctxt *Link, f *obj.Biobuf, pkg string, pn string) {
 	if v == 1 {
 		v = ctxt.Version
 	}
-	s := Linklookup(ctxt, name, v)
+	s := lookupRef(ctxt, name, v)
 	ctxt.CurRefs = append(ctxt.CurRefs, s)
 
+	if ctxt.CurVersion >= 5 {
+		flags := rdint(f)
+		if flags&^1 != 0 {
+			log.Fatalf("%s: invalid reference flags %#x for %s", pn, flags, name)
+		}
+		if flags&1 != 0 {
+			addweakref(ctxt)
+		}
+	}
+
 	if s == nil || v != 0 {
 		return
 	}
@@ -358,16 +662,49 @@ func readref(ctxt *Link, f *obj.Biobuf, pkg string, pn string) {
 	}
 }
 
+// A symRef identifies a symbol by name and version.
+type symRef struct {
+	name string
+	v    int
+}
+
+// lookupRef is like Linklookup, but caches the symbols it finds
+// in ctxt.refCache so that repeated references to the same symbol
+// in one object file are resolved only once.
+func lookupRef(ctxt *Link, name string, v int) *LSym {
+	k := symRef{name, v}
+	if s, ok := ctxt.refCache[k]; ok {
+		return s
+	}
+	s := Linklookup(ctxt, name, v)
+	if ctxt.refCache == nil {
+		ctxt.refCache = make(map[symRef]*LSym)
+	}
+	ctxt.refCache[k] = s
+	return s
+}
+
+// An objError describes malformed input found while reading a symbol.
+type objError string
+
+func (e objError) Error() string { return string(e) }
+
+// objFatalf reports malformed input by panicking with an objError.
+// ldobjfile turns the panic into a fatal error; LoadSymbol returns it.
+func objFatalf(format string, args ...interface{}) {
+	panic(objError(fmt.Sprintf(format, args...)))
+}
+
 func rdint64(f *obj.Biobuf) int64 {
 	r := f.Reader()
 	uv := uint64(0)
 	for shift := uint(0); ; shift += 7 {
 		if shift >= 64 {
-			log.Fatalf("corrupt input")
+			objFatalf("corrupt input")
 		}
 		c, err := r.ReadByte()
 		if err != nil {
-			log.Fatalln("error reading input: ", err)
+			objFatalf("error reading input: %v", err)
 		}
 		uv |= uint64(c&0x7F) << shift
 		if c&0x80 == 0 {
@@ -381,7 +718,7 @@ func rdint64(f *obj.Biobuf) int64 {
 func rdint(f *obj.Biobuf) int {
 	n := rdint64(f)
 	if int64(int(n)) != n {
-		log.Panicf("%v out of range for int", n)
+		objFatalf("%v out of range for int", n)
 	}
 	return int(n)
 }
@@ -389,7 +726,7 @@ func rdint(f *obj.Biobuf) int {
 func rdint32(f *obj.Biobuf) int32 {
 	n := rdint64(f)
 	if int64(int32(n)) != n {
-		log.Panicf("%v out of range for int32", n)
+		objFatalf("%v out of range for int32", n)
 	}
 	return int32(n)
 }
@@ -397,7 +734,7 @@ func rdint32(f *obj.Biobuf) int32 {
 func rdint16(f *obj.Biobuf) int16 {
 	n := rdint64(f)
 	if int64(int16(n)) != n {
-		log.Panicf("%v out of range for int16", n)
+		objFatalf("%v out of range for int16", n)
 	}
 	return int16(n)
 }
@@ -405,7 +742,7 @@ func rdint16(f *obj.Biobuf) int16 {
 func rduint8(f *obj.Biobuf) uint8 {
 	n := rdint64(f)
 	if int64(uint8(n)) != n {
-		log.Panicf("%v out of range for uint8", n)
+		objFatalf("%v out of range for uint8", n)
 	}
 	return uint8(n)
 }
@@ -416,6 +753,9 @@ var emptyPkg = []byte(`"".`)
 
 func rdstring(f *obj.Biobuf) string {
 	n := rdint(f)
+	if n < 0 {
+		objFatalf("invalid string length %d", n)
+	}
 	if len(rdBuf) < n {
 		rdBuf = make([]byte, n)
 	}
@@ -425,6 +765,9 @@ func rdstring(f *obj.Biobuf) string {
 
 func rddata(f *obj.Biobuf, buf *[]byte) []byte {
 	n := rdint(f)
+	if n < 0 || n > len(*buf) {
+		objFatalf("data block length %d out of range [0,%d]", n, len(*buf))
+	}
 	p := (*buf)[:n:n]
 	*buf = (*buf)[n:]
 	return p
@@ -462,7 +805,18 @@ func rdsymName(f *obj.Biobuf, pkg string) string {
 	return name
 }
 
+// addweakref marks the most recently read symbol reference as weak.
+func addweakref(ctxt *Link) {
+	if ctxt.CurWeak == nil {
+		ctxt.CurWeak = make(map[int]bool)
+	}
+	ctxt.CurWeak[len(ctxt.CurRefs)-1] = true
+}
+
 func rdsym(ctxt *Link, f *obj.Biobuf, pkg string) *LSym {
 	i := rdint(f)
+	if i < 0 || i >= len(ctxt.CurRefs) {
+		objFatalf("symbol reference %d out of range [0,%d)", i, len(ctxt.CurRefs))
+	}
 	return ctxt.CurRefs[i]
 }
@@ -0,0 +1,806 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ld
+
+import (
+	"bytes"
+	"cmd/internal/obj"
+	"compress/flate"
+	"fmt"
+	"internal/testenv"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// objWriter encodes a Go object file in the format read by ldobjfile.
+type objWriter struct {
+	bytes.Buffer
+}
+
+func (w *objWriter) int(v int64) {
+	uv := uint64(v<<1) ^ uint64(v>>63)
+	for uv >= 0x80 {
+		w.WriteByte(byte(uv) | 0x80)
+		uv >>= 7
+	}
+	w.WriteByte(byte(uv))
+}
+
+func (w *objWriter) string(s string) {
+	w.int(int64(len(s)))
+	w.WriteString(s)
+}
+
+func (w *objWriter) header(version byte) {
+	w.WriteString(startmagic)
+	w.WriteByte(version)
+	w.string("") // no dependencies
+}
+
+func (w *objWriter) ref(name string, version int64) {
+	w.WriteByte(0xfe)
+	w.string(name)
+	w.int(version)
+}
+
+// testSym describes a data symbol written by objWriter.sym.
+type testSym struct {
+	ref        int64 // symref index
+	flags      int64
+	visibility int64
+	data       []byte
+	datalen    int64   // declared data length, if flags&16 != 0
+	relocs     []int64 // symref indexes of relocation targets
+	keep       []int64 // symref indexes of keep-alive edges, if flags&32 != 0
+}
+
+func (w *objWriter) sym(s testSym) {
+	w.WriteByte(0xfe)
+	w.int(obj.SDATA)
+	w.int(s.ref)
+	w.int(s.flags)
+	if s.flags&4 != 0 {
+		w.int(s.visibility)
+	}
+	w.int(int64(len(s.data)))
+	w.int(0) // gotype
+	w.int(int64(len(s.data)))
+	if s.flags&16 != 0 {
+		w.int(s.datalen)
+	}
+	if s.flags&32 != 0 {
+		w.int(int64(len(s.keep)))
+		for _, k := range s.keep {
+			w.int(k)
+		}
+	}
+	w.int(int64(len(s.relocs)))
+	for i, r := range s.relocs {
+		w.int(int64(i * 8)) // off
+		w.int(8)            // siz
+		w.int(obj.R_ADDR)
+		w.int(0) // add
+		w.int(r)
+	}
+}
+
+// testFunc describes the optional parts of a function written by writeTextSym.
+type testFunc struct {
+	files    []int64 // symref indexes of source files
+	clobbers int64   // length of the register-clobber bitmap, if flags&(1<<3) != 0
+	order    int64   // ordering hint, if flags&(1<<4) != 0
+	zpcln    []int64 // lengths of the compressed pcsp, pcfile, and pcline, if flags&(1<<5) != 0
+	sect     string  // text section name, if flags&(1<<6) != 0
+}
+
+// writeTextSym writes a one-byte function whose symref index is ref.
+// Its code and any data blocks in opts are taken from the data
+// block in order.
+func writeTextSym(w *objWriter, ref int64, opts testFunc) {
+	var flags int64
+	if opts.clobbers != 0 {
+		flags |= 1 << 3
+	}
+	if opts.order != 0 {
+		flags |= 1 << 4
+	}
+	if opts.zpcln != nil {
+		flags |= 1 << 5
+	}
+	if opts.sect != "" {
+		flags |= 1 << 6
+	}
+	w.WriteByte(0xfe)
+	w.int(obj.STEXT)
+	w.int(ref)
+	w.int(0) // flags
+	w.int(1) // size
+	w.int(0) // gotype
+	w.int(1) // data
+	w.int(0) // relocations
+	w.int(0) // args
+	w.int(0) // locals
+	w.int(0) // nosplit
+	w.int(flags)
+	w.int(0) // automatics
+	if opts.zpcln != nil {
+		for _, n := range opts.zpcln {
+			w.int(n)
+		}
+	} else {
+		w.int(0) // pcsp
+		w.int(0) // pcfile
+		w.int(0) // pcline
+	}
+	w.int(0) // pcdata
+	w.int(0) // funcdata
+	w.int(int64(len(opts.files)))
+	for _, f := range opts.files {
+		w.int(f)
+	}
+	if opts.clobbers != 0 {
+		w.int(opts.clobbers)
+	}
+	if opts.order != 0 {
+		w.int(opts.order)
+	}
+	if opts.sect != "" {
+		w.string(opts.sect)
+	}
+}
+
+// tempObj writes the object file in w to a temporary file.
+// The caller must call cleanup to remove it.
+func tempObj(t testing.TB, w *objWriter) (name string, cleanup func()) {
+	dir, err := ioutil.TempDir("", "objfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name = filepath.Join(dir, "x.o")
+	if err := ioutil.WriteFile(name, w.Bytes(), 0666); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return name, func() { os.RemoveAll(dir) }
+}
+
+// loadObj writes the object file in w to a temporary file
+// and reads it into ctxt as package pkg.
+func loadObj(t *testing.T, ctxt *Link, w *objWriter, pkg string) {
+	name, cleanup := tempObj(t, w)
+	defer cleanup()
+	f, err := obj.Bopenr(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer obj.Bterm(f)
+	ldobjfile(ctxt, f, pkg, int64(w.Len()), name)
+}
+
+func newTestLink() *Link {
+	return &Link{Hash: []map[string]*LSym{make(map[string]*LSym)}}
+}
+
+// writeSyms encodes an object file of the given version defining
+// one data symbol per element of syms, named by names.
+func writeSyms(version byte, names []string, syms []testSym) *objWriter {
+	w := new(objWriter)
+	w.header(version)
+	for _, name := range names {
+		w.ref(name, 0)
+		if version >= 5 {
+			w.int(0) // reference flags
+		}
+	}
+	w.WriteByte(0xff)
+	var data []byte
+	for _, s := range syms {
+		data = append(data, s.data...)
+	}
+	w.int(int64(len(data)))
+	w.Write(data)
+	for _, s := range syms {
+		w.sym(s)
+	}
+	w.WriteString(endmagic)
+	return w
+}
+
+func TestReadSymVisibility(t *testing.T) {
+	names := []string{`"".plain`, `"".hidden`, `"".exported`}
+	syms := []testSym{
+		{ref: 1, data: []byte{1}},
+		{ref: 2, flags: 4, visibility: 1, data: []byte{2, 3}},
+		{ref: 3, flags: 4 | 1, visibility: 2, data: []byte{4}},
+	}
+	ctxt := newTestLink()
+	loadObj(t, ctxt, writeSyms(2, names, syms), "p")
+
+	tests := []struct {
+		name             string
+		hidden, exported bool
+		data             []byte
+	}{
+		{"p.plain", false, false, []byte{1}},
+		{"p.hidden", true, false, []byte{2, 3}},
+		{"p.exported", false, true, []byte{4}},
+	}
+	for _, tt := range tests {
+		s := Linkrlookup(ctxt, tt.name, 0)
+		if s == nil {
+			t.Errorf("symbol %s not loaded", tt.name)
+			continue
+		}
+		if s.Attr.Hidden() != tt.hidden || s.Attr.Exported() != tt.exported {
+			t.Errorf("%s: Hidden()=%v Exported()=%v, want %v %v", tt.name, s.Attr.Hidden(), s.Attr.Exported(), tt.hidden, tt.exported)
+		}
+		if !bytes.Equal(s.P, tt.data) {
+			t.Errorf("%s: data %v, want %v", tt.name, s.P, tt.data)
+		}
+	}
+	if s := Linkrlookup(ctxt, "p.exported", 0); !s.Attr.DuplicateOK() {
+		t.Errorf("p.exported: lost dupok flag")
+	}
+}
+
+func TestReadSymVersion1(t *testing.T) {
+	names := []string{`"".x`}
+	syms := []testSym{{ref: 1, flags: 2, data: []byte{7}}}
+	ctxt := newTestLink()
+	loadObj(t, ctxt, writeSyms(1, names, syms), "p")
+	s := Linkrlookup(ctxt, "p.x", 0)
+	if s == nil {
+		t.Fatal("symbol p.x not loaded")
+	}
+	if !s.Attr.Local() || s.Attr.Hidden() || s.Attr.Exported() {
+		t.Errorf("p.x: Local()=%v Hidden()=%v Exported()=%v, want true false false", s.Attr.Local(), s.Attr.Hidden(), s.Attr.Exported())
+	}
+}
+
+func TestLoadSymbol(t *testing.T) {
+	names := []string{`"".a`, `"".b`, `"".c`}
+	syms := []testSym{
+		{ref: 1, data: []byte{1, 2}, relocs: []int64{2}},
+		{ref: 2, flags: 4, visibility: 1, data: []byte{3, 4, 5}, relocs: []int64{1, 3}},
+		{ref: 3, data: []byte{6}},
+	}
+	w := writeSyms(2, names, syms)
+	ctxt := newTestLink()
+	loadObj(t, ctxt, w, `""`)
+
+	name, cleanup := tempObj(t, w)
+	defer cleanup()
+
+	for _, symname := range names {
+		f, err := obj.Bopenr(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := LoadSymbol(f, symname)
+		obj.Bterm(f)
+		if err != nil {
+			t.Errorf("LoadSymbol(%s): %v", symname, err)
+			continue
+		}
+		want := Linkrlookup(ctxt, symname, 0)
+		if got.Name != want.Name || got.Type != want.Type || got.Size != want.Size || got.Attr != want.Attr {
+			t.Errorf("LoadSymbol(%s) = %s type=%d size=%d attr=%#x, want %s type=%d size=%d attr=%#x",
+				symname, got.Name, got.Type, got.Size, got.Attr, want.Name, want.Type, want.Size, want.Attr)
+		}
+		if !bytes.Equal(got.P, want.P) {
+			t.Errorf("LoadSymbol(%s): data %v, want %v", symname, got.P, want.P)
+		}
+		if len(got.R) != len(want.R) {
+			t.Errorf("LoadSymbol(%s): %d relocations, want %d", symname, len(got.R), len(want.R))
+			continue
+		}
+		for i := range got.R {
+			if got.R[i].Off != want.R[i].Off || got.R[i].Sym.Name != want.R[i].Sym.Name {
+				t.Errorf("LoadSymbol(%s): reloc %d = %d %s, want %d %s", symname, i, got.R[i].Off, got.R[i].Sym.Name, want.R[i].Off, want.R[i].Sym.Name)
+			}
+		}
+	}
+
+	f, err := obj.Bopenr(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer obj.Bterm(f)
+	if s, err := LoadSymbol(f, `"".missing`); err == nil {
+		t.Errorf("LoadSymbol(missing) = %v, want error", s.Name)
+	}
+}
+
+func TestLoadSymbolCorrupt(t *testing.T) {
+	names := []string{`"".a`, `"".b`}
+	valid := writeSyms(2, names, []testSym{
+		{ref: 1, data: []byte{1, 2}},
+		{ref: 2, data: []byte{3}},
+	}).Bytes()
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"truncated", valid[:len(valid)-len(endmagic)-2], "error reading input"},
+		{"flags", writeSyms(2, names, []testSym{{ref: 2, flags: 64}}).Bytes(), `invalid flags 0x40 for "".b`},
+		{"symref", writeSyms(2, names, []testSym{{ref: 9}}).Bytes(), "symbol reference 9 out of range"},
+	}
+	for _, tt := range tests {
+		w := new(objWriter)
+		w.Write(tt.data)
+		name, cleanup := tempObj(t, w)
+		f, err := obj.Bopenr(name)
+		if err != nil {
+			cleanup()
+			t.Fatal(err)
+		}
+		s, err := LoadSymbol(f, `"".b`)
+		obj.Bterm(f)
+		cleanup()
+		if err == nil {
+			t.Errorf("%s: LoadSymbol = %v, want error", tt.name, s.Name)
+		} else if !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("%s: LoadSymbol error %q, want prefix %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestReadSymFlags(t *testing.T) {
+	names := []string{`"".all`, `"".ro`}
+	syms := []testSym{
+		{ref: 1, flags: 1 | 2 | 4 | 8, visibility: 1, data: []byte{1}},
+		{ref: 2, flags: 8, data: []byte{2}},
+	}
+	ctxt := newTestLink()
+	loadObj(t, ctxt, writeSyms(3, names, syms), "p")
+
+	tests := []struct {
+		name string
+		want Attribute
+	}{
+		{"p.all", AttrDuplicateOK | AttrLocal | AttrHidden | AttrReadOnly},
+		{"p.ro", AttrReadOnly},
+	}
+	const mask = AttrDuplicateOK | AttrLocal | AttrHidden | AttrExported | AttrReadOnly
+	for _, tt := range tests {
+		s := Linkrlookup(ctxt, tt.name, 0)
+		if s == nil {
+			t.Errorf("symbol %s not loaded", tt.name)
+			continue
+		}
+		if got := s.Attr & mask; got != tt.want {
+			t.Errorf("%s: attributes %#x, want %#x", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRemapFile(t *testing.T) {
+	const abs = "/home/gopher/src/p/x.go"
+
+	w := new(objWriter)
+	w.header(1)
+	w.ref(`"".f`, 0)
+	w.ref(abs, 1)
+	w.WriteByte(0xff)
+	w.int(1) // data length
+	w.WriteByte(0xc3)
+	writeTextSym(w, 1, testFunc{files: []int64{2}})
+	w.WriteString(endmagic)
+
+	ctxt := newTestLink()
+	ctxt.RemapFile = func(path string) string {
+		return strings.TrimPrefix(path, "/home/gopher/src/")
+	}
+	loadObj(t, ctxt, w, "p")
+
+	s := Linkrlookup(ctxt, "p.f", 0)
+	if s == nil || s.Pcln == nil {
+		t.Fatal("function p.f not loaded")
+	}
+	if len(s.Pcln.File) != 1 {
+		t.Fatalf("p.f has %d files, want 1", len(s.Pcln.File))
+	}
+	if got, want := s.Pcln.File[0].Name, "p/x.go"; got != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+	if got, want := int(s.Pcln.File[0].Version), ctxt.Version; got != want {
+		t.Errorf("file version = %d, want %d", got, want)
+	}
+}
+
+func TestReadSymDataLength(t *testing.T) {
+	names := []string{`"".x`, `"".y`}
+	syms := []testSym{
+		{ref: 1, flags: 16, data: []byte{1, 2, 3}, datalen: 3},
+		{ref: 2, flags: 16 | 1, data: nil, datalen: 0},
+	}
+	ctxt := newTestLink()
+	loadObj(t, ctxt, writeSyms(4, names, syms), "p")
+	if s := Linkrlookup(ctxt, "p.x", 0); s == nil || !bytes.Equal(s.P, []byte{1, 2, 3}) {
+		t.Errorf("p.x not loaded correctly")
+	}
+
+	// A mismatch is fatal, so check it in a subprocess.
+	testenv.MustHaveExec(t)
+	if os.Getenv("GO_LD_TEST_DATALEN") == "1" {
+		syms[0].datalen = 5
+		loadObj(t, newTestLink(), writeSyms(4, names, syms), "p")
+		os.Exit(0)
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=TestReadSymDataLength")
+	cmd.Env = append(os.Environ(), "GO_LD_TEST_DATALEN=1")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("loading mismatched data length succeeded:\n%s", out)
+	}
+	if want := "symbol p.x data length mismatch: declared 5, got 3"; !strings.Contains(string(out), want) {
+		t.Errorf("output does not contain %q:\n%s", want, out)
+	}
+}
+
+func TestReadWeakRef(t *testing.T) {
+	// writeRef encodes an object file defining name with one
+	// relocation to p.missing, using the given reference flags.
+	writeRef := func(name string, flags int64) *objWriter {
+		w := new(objWriter)
+		w.header(5)
+		w.ref(name, 0)
+		w.int(0) // reference flags
+		w.ref(`"".missing`, 0)
+		w.int(flags)
+		w.WriteByte(0xff)
+		w.int(8)
+		w.Write(make([]byte, 8))
+		w.sym(testSym{ref: 1, data: make([]byte, 8), relocs: []int64{2}})
+		w.WriteString(endmagic)
+		return w
+	}
+
+	ctxt := newTestLink()
+	loadObj(t, ctxt, writeRef(`"".weak`, 1), "p")
+	loadObj(t, ctxt, writeRef(`"".strong`, 0), "p")
+
+	weak := Linkrlookup(ctxt, "p.weak", 0)
+	strong := Linkrlookup(ctxt, "p.strong", 0)
+	if weak == nil || strong == nil {
+		t.Fatal("symbols p.weak and p.strong not loaded")
+	}
+	if len(weak.R) != 1 || len(strong.R) != 1 {
+		t.Fatalf("got %d and %d relocations, want 1 each", len(weak.R), len(strong.R))
+	}
+	if !weak.R[0].Weak {
+		t.Errorf("relocation in p.weak is strong, want weak")
+	}
+	if strong.R[0].Weak {
+		t.Errorf("relocation in p.strong is weak, want strong")
+	}
+	missing := weak.R[0].Sym
+	if missing.Name != "p.missing" || strong.R[0].Sym != missing {
+		t.Fatalf("relocations target %s and %s, want p.missing", missing.Name, strong.R[0].Sym.Name)
+	}
+
+	defer func(c *Link, n int) { Ctxt, nerrors = c, n }(Ctxt, nerrors)
+	Ctxt = ctxt
+	nerrors = 0
+	missing.Attr |= AttrReachable
+
+	// Resolving the weak reference must leave p.missing undefined,
+	// so that the strong reference is still reported.
+	relocsym(weak)
+	if missing.Type != 0 {
+		t.Errorf("p.missing has type %d after relocation, want undefined", missing.Type)
+	}
+	undefsym(weak)
+	if nerrors != 0 {
+		t.Errorf("weak reference to p.missing reported as undefined")
+	}
+	undefsym(strong)
+	if nerrors != 1 {
+		t.Errorf("strong reference to p.missing not reported as undefined")
+	}
+}
+
+func TestReadClobbers(t *testing.T) {
+	w := new(objWriter)
+	w.header(6)
+	for _, name := range []string{`"".f`, `"".g`} {
+		w.ref(name, 0)
+		w.int(0) // reference flags
+	}
+	w.WriteByte(0xff)
+	w.int(4) // data length
+	w.Write([]byte{0xc3, 0xc3, 0x05, 0x80})
+
+	writeTextSym(w, 1, testFunc{})
+	writeTextSym(w, 2, testFunc{clobbers: 2})
+	w.WriteString(endmagic)
+
+	ctxt := newTestLink()
+	loadObj(t, ctxt, w, "p")
+
+	if f := Linkrlookup(ctxt, "p.f", 0); f == nil {
+		t.Error("function p.f not loaded")
+	} else if f.Clobbers != nil {
+		t.Errorf("p.f clobbers = %x, want none", f.Clobbers)
+	}
+	g := Linkrlookup(ctxt, "p.g", 0)
+	if g == nil {
+		t.Fatal("function p.g not loaded")
+	}
+	if want := []byte{0x05, 0x80}; !bytes.Equal(g.Clobbers, want) {
+		t.Errorf("p.g clobbers = %x, want %x", g.Clobbers, want)
+	}
+
+	name, cleanup := tempObj(t, w)
+	defer cleanup()
+	f, err := obj.Bopenr(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer obj.Bterm(f)
+	s, err := LoadSymbol(f, `"".g`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0x05, 0x80}; !bytes.Equal(s.Clobbers, want) {
+		t.Errorf("LoadSymbol: clobbers = %x, want %x", s.Clobbers, want)
+	}
+}
+
+func TestReadOrder(t *testing.T) {
+	w := new(objWriter)
+	w.header(7)
+	for _, name := range []string{`"".f`, `"".g`} {
+		w.ref(name, 0)
+		w.int(0) // reference flags
+	}
+	w.WriteByte(0xff)
+	w.int(2) // data length
+	w.Write([]byte{0xc3, 0xc3})
+
+	writeTextSym(w, 1, testFunc{})
+	writeTextSym(w, 2, testFunc{order: -3})
+	w.WriteString(endmagic)
+
+	ctxt := newTestLink()
+	loadObj(t, ctxt, w, "p")
+
+	if f := Linkrlookup(ctxt, "p.f", 0); f == nil {
+		t.Error("function p.f not loaded")
+	} else if f.Order != 0 {
+		t.Errorf("p.f order = %d, want 0", f.Order)
+	}
+	if g := Linkrlookup(ctxt, "p.g", 0); g == nil {
+		t.Error("function p.g not loaded")
+	} else if g.Order != -3 {
+		t.Errorf("p.g order = %d, want -3", g.Order)
+	}
+
+	name, cleanup := tempObj(t, w)
+	defer cleanup()
+	f, err := obj.Bopenr(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer obj.Bterm(f)
+	s, err := LoadSymbol(f, `"".g`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Order != -3 {
+		t.Errorf("LoadSymbol: order = %d, want -3", s.Order)
+	}
+}
+
+func TestReadCompressedPcln(t *testing.T) {
+	tables := [][]byte{
+		bytes.Repeat([]byte{0x02, 0x08}, 100), // pcsp
+		bytes.Repeat([]byte{0x01}, 200),       // pcfile
+		bytes.Repeat([]byte{0x02, 0x01}, 100), // pcline
+	}
+	var blocks [][]byte
+	for _, p := range tables {
+		var b bytes.Buffer
+		zw, err := flate.NewWriter(&b, flate.BestCompression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zw.Write(p)
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		blocks = append(blocks, b.Bytes())
+	}
+
+	w := new(objWriter)
+	w.header(8)
+	w.ref(`"".f`, 0)
+	w.int(0) // reference flags
+	w.WriteByte(0xff)
+	data := []byte{0xc3}
+	for _, b := range blocks {
+		data = append(data, b...)
+	}
+	w.int(int64(len(data)))
+	w.Write(data)
+	var zpcln []int64
+	for _, b := range blocks {
+		zpcln = append(zpcln, int64(len(b)))
+	}
+	writeTextSym(w, 1, testFunc{zpcln: zpcln})
+	w.WriteString(endmagic)
+
+	ctxt := newTestLink()
+	loadObj(t, ctxt, w, "p")
+
+	f := Linkrlookup(ctxt, "p.f", 0)
+	if f == nil {
+		t.Fatal("function p.f not loaded")
+	}
+	got := [][]byte{f.Pcln.Pcsp.P, f.Pcln.Pcfile.P, f.Pcln.Pcline.P}
+	for i, name := range []string{"pcsp", "pcfile", "pcline"} {
+		if !bytes.Equal(got[i], tables[i]) {
+			t.Errorf("%s = %x, want %x", name, got[i], tables[i])
+		}
+	}
+}
+
+func TestReadRefVersions(t *testing.T) {
+	w := new(objWriter)
+	w.header(1)
+	w.ref(`"".x`, 0)
+	w.ref(`"".x`, 1) // file-local
+	w.ref(`"".x`, 0)
+	w.WriteByte(0xff)
+	w.int(0)
+	w.WriteString(endmagic)
+
+	ctxt := newTestLink()
+	loadObj(t, ctxt, w, "p")
+
+	refs := ctxt.CurRefs
+	if len(refs) != 4 {
+		t.Fatalf("read %d references, want 4", len(refs))
+	}
+	if refs[1] != refs[3] {
+		t.Errorf("references to p.x resolved to different symbols")
+	}
+	if refs[1] == refs[2] || refs[2].Version != int16(ctxt.Version) {
+		t.Errorf("file-local p.x resolved to %v, want version %d", refs[2], ctxt.Version)
+	}
+}
+
+func BenchmarkReadRefs(b *testing.B) {
+	w := new(objWriter)
+	w.header(1)
+	for i := 0; i < 10000; i++ {
+		w.ref(fmt.Sprintf(`"".sym%d`, i%10), int64(i%2))
+	}
+	w.WriteByte(0xff)
+	w.int(0)
+	w.WriteString(endmagic)
+	name, cleanup := tempObj(b, w)
+	defer cleanup()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f, err := obj.Bopenr(name)
+		if err != nil {
+			b.Fatal(err)
+		}
+		ldobjfile(newTestLink(), f, "p", int64(w.Len()), name)
+		obj.Bterm(f)
+	}
+}
+
+func TestReadKeepEdges(t *testing.T) {
+	names := []string{`"".x`, `"".y`, `"".z`, `q.ext`}
+	syms := []testSym{
+		{ref: 1, flags: 32, data: []byte{1}, keep: []int64{2, 4}},
+		{ref: 2, data: []byte{2}},
+		{ref: 3, flags: 32, data: []byte{3}, relocs: []int64{1}},
+	}
+	w := writeSyms(9, names, syms)
+	ctxt := newTestLink()
+	loadObj(t, ctxt, w, "p")
+
+	x, y := Linkrlookup(ctxt, "p.x", 0), Linkrlookup(ctxt, "p.y", 0)
+	if x == nil || y == nil {
+		t.Fatal("symbols p.x and p.y not loaded")
+	}
+	ext := Linkrlookup(ctxt, "q.ext", 0)
+	if len(x.Keep) != 2 || x.Keep[0] != y || x.Keep[1] != ext {
+		t.Errorf("p.x keeps %v, want [p.y q.ext]", x.Keep)
+	}
+	if len(y.Keep) != 0 {
+		t.Errorf("p.y keeps %v, want none", y.Keep)
+	}
+	z := Linkrlookup(ctxt, "p.z", 0)
+	if z == nil || len(z.Keep) != 0 || len(z.R) != 1 || z.R[0].Sym != x {
+		t.Errorf("p.z not loaded correctly")
+	}
+
+	// Skipping a symbol must consume its keep-alive edges.
+	name, cleanup := tempObj(t, w)
+	defer cleanup()
+	f, err := obj.Bopenr(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer obj.Bterm(f)
+	s, err := LoadSymbol(f, `"".z`)
+	if err != nil {
+		t.Fatalf("LoadSymbol(z): %v", err)
+	}
+	if !bytes.Equal(s.P, []byte{3}) {
+		t.Errorf("LoadSymbol(z): data %v, want [3]", s.P)
+	}
+}
+
+func TestReadTextSections(t *testing.T) {
+	names := []string{`"".f`, `"".hot1`, `"".g`, `"".cold`, `"".hot2`}
+	w := new(objWriter)
+	w.header(10)
+	for _, name := range names {
+		w.ref(name, 0)
+		w.int(0) // reference flags
+	}
+	w.WriteByte(0xff)
+	w.int(int64(len(names))) // data length
+	w.Write(bytes.Repeat([]byte{0xc3}, len(names)))
+
+	sects := []string{"", "hot", "", "cold", "hot"}
+	for i, sect := range sects {
+		writeTextSym(w, int64(i+1), testFunc{sect: sect})
+	}
+	w.WriteString(endmagic)
+
+	ctxt := newTestLink()
+	loadObj(t, ctxt, w, "p")
+
+	list := func(s *LSym) string {
+		var names []string
+		for ; s != nil; s = s.Next {
+			names = append(names, s.Name)
+		}
+		return strings.Join(names, " ")
+	}
+	if got, want := list(ctxt.Textp), "p.f p.g"; got != want {
+		t.Errorf("default text section = %s, want %s", got, want)
+	}
+	var got []string
+	for _, sect := range ctxt.Textsects {
+		got = append(got, sect.Name+": "+list(sect.Textp))
+	}
+	if want := []string{"hot: p.hot1 p.hot2", "cold: p.cold"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("named text sections = %q, want %q", got, want)
+	}
+
+	ctxt.appendTextsects()
+	if got, want := list(ctxt.Textp), "p.f p.g p.hot1 p.hot2 p.cold"; got != want {
+		t.Errorf("text after appendTextsects = %s, want %s", got, want)
+	}
+	if ctxt.Etextp == nil || ctxt.Etextp.Name != "p.cold" || len(ctxt.Textsects) != 0 {
+		t.Errorf("appendTextsects left Etextp = %v, %d sections", ctxt.Etextp, len(ctxt.Textsects))
+	}
+
+	// Skipping a function must consume its section name.
+	name, cleanup := tempObj(t, w)
+	defer cleanup()
+	f, err := obj.Bopenr(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer obj.Bterm(f)
+	if _, err := LoadSymbol(f, `"".hot2`); err != nil {
+		t.Errorf("LoadSymbol(hot2): %v", err)
+	}
+}
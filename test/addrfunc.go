@@ -0,0 +1,23 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test the error for taking the address of a function.
+
+package p
+
+func someFunc() {}
+
+type T struct{}
+
+func (T) m() {}
+
+var _ = &someFunc // ERROR "cannot take the address of function someFunc"
+var _ = &T.m      // ERROR "cannot take the address of function T.m"
+
+func f() {
+	g := someFunc
+	_ = &g
+}
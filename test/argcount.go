@@ -0,0 +1,22 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that argument count errors show the argument and
+// parameter types.
+
+package p
+
+func f(a int, b string)       {}
+func g(a int, b ...string)    {}
+func two() (int, string, int) { return 0, "", 0 }
+
+func h(x float64, s []string) {
+	f(x)            // ERROR "not enough arguments in call to f\n\thave \(float64\)\n\twant \(int, string\)"
+	f(1, "a", x)    // ERROR "too many arguments in call to f\n\thave \(untyped number, untyped string, float64\)\n\twant \(int, string\)"
+	f(two())        // ERROR "too many arguments in call to f\n\thave \(int, string, int\)\n\twant \(int, string\)"
+	g()             // ERROR "not enough arguments in call to g\n\thave \(\)\n\twant \(int, \.\.\.string\)"
+	g(1, "a", s...) // ERROR "too many arguments in call to g\n\thave \(untyped number, untyped string, \[\]string\.\.\.\)\n\twant \(int, \.\.\.string\)"
+}
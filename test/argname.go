@@ -0,0 +1,26 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that argument type errors name the parameter when it has a name.
+
+package p
+
+import "strings"
+
+func f(count int, name string) {}
+func g(int, string)            {}
+func h(a, b int, rest ...int)  {}
+func pair() (int, int)         { return 0, 0 }
+
+func _() {
+	f(1, 2)      // ERROR "cannot use 2 \(type int\) as type string in argument name to f"
+	g(1, 2)      // ERROR "cannot use 2 \(type int\) as type string in argument to g"
+	h(1, "x")    // ERROR "in argument b to h"
+	h(1, 2, "y") // ERROR "in argument rest to h"
+	f(pair())    // ERROR "cannot use int as type string in argument name to f"
+
+	strings.Repeat("a", "b") // ERROR "cannot use .b. \(type string\) as type int in argument count to strings.Repeat"
+}
@@ -0,0 +1,22 @@
+// errorcheck -0 -d=argsize=65536
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=argsize reports large by-value arguments.
+
+package p
+
+type Big [1 << 20]byte
+
+type Small [1 << 10]byte
+
+func f(int, Big, *Big, Small) {}
+
+func g(b Big, s Small) {
+	f(1, b, &b, s)      // ERROR "argument 2 to f has type Big of 1048576 bytes; consider passing a pointer"
+	f(1, Big{}, nil, s) // ERROR "argument 2 to f has type Big of 1048576 bytes"
+	f(1,
+		Big{}, nil, s) // ERROR "argument 2 to f has type Big of 1048576 bytes"
+}
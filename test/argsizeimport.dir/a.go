@@ -0,0 +1,13 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package a
+
+type Big [1 << 20]byte
+
+func take(Big) {}
+
+func F(b *Big) {
+	take(*b) // ERROR "argument 1 to take has type Big of 1048576 bytes"
+}
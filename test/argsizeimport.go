@@ -0,0 +1,10 @@
+// errorcheckdir -0 -d=argsize=65536
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=argsize does not report large arguments
+// in inlined function bodies imported from another package.
+
+package ignored
@@ -0,0 +1,21 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test the note for assignments between channels
+// with incompatible directions.
+
+package p
+
+func f(c chan int, s chan<- int, r <-chan int) {
+	s = c
+	r = c
+	c = s // ERROR "cannot assign chan<- int to chan int \(channel direction mismatch\)"
+	c = r // ERROR "cannot assign <-chan int to chan int \(channel direction mismatch\)"
+	s = r // ERROR "cannot assign <-chan int to chan<- int \(channel direction mismatch\)"
+	g(s)  // ERROR "channel direction mismatch"
+}
+
+func g(chan int)
@@ -0,0 +1,31 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that comparing an interface with a concrete type
+// that does not implement it explains why.
+
+package p
+
+type T struct{}
+
+func (*T) Error() string { return "" }
+
+type U struct{}
+
+var (
+	e error
+	x int
+	t T
+	u U
+)
+
+var (
+	_ = e == x // ERROR "impossible comparison: int does not implement error .missing Error method."
+	_ = e != t // ERROR "impossible comparison: T does not implement error .Error method has pointer receiver."
+	_ = u == e // ERROR "impossible comparison: U does not implement error .missing Error method."
+	_ = e == nil
+	_ = e == &t
+)
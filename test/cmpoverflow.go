@@ -0,0 +1,25 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that comparing a sized integer with an untyped constant
+// that does not fit in its type is reported at the comparison.
+
+package p
+
+const big = 1 << 40
+
+var (
+	x int32
+	u uint8
+)
+
+var (
+	_ = x == big  // ERROR "constant 1099511627776 overflows int32 in comparison"
+	_ = big > x   // ERROR "constant 1099511627776 overflows int32 in comparison"
+	_ = x < 1<<40 // ERROR "constant 1099511627776 overflows int32 in comparison"
+	_ = u >= -1   // ERROR "constant -1 overflows uint8 in comparison"
+	_ = x == 1<<30
+)
@@ -0,0 +1,45 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that comparing an incomparable struct names the offending field.
+
+package p
+
+type A struct {
+	x int
+	b B
+}
+
+type B struct {
+	c func()
+}
+
+type C struct {
+	n int
+	s []int
+}
+
+type D struct {
+	B
+}
+
+type E struct {
+	a [2]map[int]int
+}
+
+var (
+	a A
+	c C
+	d D
+	e E
+)
+
+var (
+	_ = a == a // ERROR "struct field b.c of type func\(\) cannot be compared"
+	_ = c == c // ERROR "struct field s of type \[\]int cannot be compared"
+	_ = d != d // ERROR "struct field B.c of type func\(\) cannot be compared"
+	_ = e == e // ERROR "struct field a of type \[2\]map\[int\]int cannot be compared"
+)
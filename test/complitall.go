@@ -0,0 +1,22 @@
+// errorcheck -d=complit
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=complit reports every error in a composite
+// literal, not just an invalid literal type.
+
+package p
+
+type T struct{ x int }
+
+type P *T
+
+type Q *int
+
+var (
+	_ = P{x: "a", y: 1} // ERROR "invalid pointer type P for composite literal" "cannot use .a. \(type string\) as type int in field value" "unknown T field .y. in struct literal"
+	_ = Q{undefined}    // ERROR "invalid pointer type Q for composite literal" "undefined: undefined"
+	_ = &T{x: 1}
+)
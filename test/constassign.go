@@ -0,0 +1,22 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that assigning to a constant is reported as such.
+
+package p
+
+const c = 1
+
+type T int
+
+const d T = 2
+
+func f() {
+	c = 2        // ERROR "cannot assign to constant c"
+	d++          // ERROR "cannot assign to constant d"
+	c, d = 1, 2  // ERROR "cannot assign to constant c" "cannot assign to constant d"
+	false = true // ERROR "cannot assign to constant false"
+}
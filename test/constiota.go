@@ -0,0 +1,40 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that constant overflow in an iota-based
+// const declaration reports the iota value.
+
+package p
+
+const (
+	a0 int8 = 1 << iota
+	a1
+	a2
+	a3
+	a4
+	a5
+	a6
+	a7 // ERROR "constant 128 \(iota=7\) overflows int8"
+)
+
+type Flags uint16
+
+const (
+	f0 Flags = 1 << (4 * iota)
+	f1
+	f2
+	f3
+	f4 // ERROR "constant 65536 \(iota=4\) overflows Flags"
+)
+
+const (
+	b0 = 1 << (32 * iota)
+	b1
+	b2
+	b3 // untyped: ok
+)
+
+const c int8 = 200 // ERROR "constant 200 overflows int8"
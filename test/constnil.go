@@ -0,0 +1,15 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test the error for nil constant initializers.
+
+package p
+
+const p *int = nil        // ERROR "const initializer cannot be nil \(use a var declaration; constants cannot be nil\)"
+const s []int = nil       // ERROR "const initializer cannot be nil \(use a var declaration"
+const m map[int]int = nil // ERROR "const initializer cannot be nil \(use a var declaration"
+const n = nil             // ERROR "const initializer cannot be nil$"
+const i int = nil         // ERROR "const initializer cannot be nil$"
@@ -0,0 +1,15 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that converting an array to a slice suggests slicing it.
+
+package p
+
+var a [3]int
+
+var _ = []int(a) // ERROR "cannot convert a \(type \[3\]int\) to type \[\]int \(to get a slice of an array, use arr\[:\]\)"
+var _ = []int(a[:])
+var _ = []byte(a) // ERROR "cannot convert a \(type \[3\]int\) to type \[\]byte$"
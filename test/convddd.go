@@ -0,0 +1,18 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test errors for ... in type conversions.
+
+package p
+
+var s []string
+var str string
+
+var (
+	_ = []byte(str...) // ERROR "invalid use of ... in \(\[\]byte\)\(str...\): ... cannot be used in a conversion"
+	_ = string(s...)   // ERROR "invalid use of ... in string\(s...\): ... cannot be used in a conversion"
+	_ = [...]int(s)    // ERROR "use of \[...\] array outside of array literal"
+)
@@ -0,0 +1,40 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Verify that conversions between struct types that differ
+// only in field tags explain why they are rejected.
+
+package p
+
+type A struct {
+	X int `json:"x"`
+	Y string
+}
+
+type B struct {
+	X int `json:"y"`
+	Y string
+}
+
+type C struct {
+	X int
+	Y string
+}
+
+type D struct {
+	X int64
+	Y string
+}
+
+var (
+	a A
+	_ = B(a) // ERROR "cannot convert a .* to type B .struct types differ only in tags."
+	_ = C(a) // ERROR "cannot convert a .* to type C .struct types differ only in tags."
+	_ = &a
+	_ = (*B)(&a) // ERROR "cannot convert &a .* to type \*B .struct types differ only in tags."
+	_ = D(a)     // ERROR "cannot convert a .* to type D$"
+	_ = A(C{})   // ERROR "struct types differ only in tags"
+)
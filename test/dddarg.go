@@ -0,0 +1,26 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Verify that spreading a value that is not a suitable
+// slice with ... is reported clearly.
+
+package p
+
+func f(args ...int)        {}
+func g(s string, x ...int) {}
+
+type ints []int
+
+func _() {
+	var x int
+	var s []string
+	f(x...)        // ERROR "cannot use x .type int. as the last argument with ... .expected \[\]int."
+	f(s...)        // ERROR "cannot use s .type \[\]string. as the last argument with ... .expected \[\]int."
+	g("a", "b"...) // ERROR "cannot use .b. .type untyped string. as the last argument with ... .expected \[\]int."
+	f(ints{1}...)
+	f([]int{1}...)
+	f(nil...)
+}
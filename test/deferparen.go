@@ -0,0 +1,16 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that go and defer of a parenthesized non-call are
+// reported as such.
+
+package p
+
+func f(x, y int) {
+	defer (x + y)   // ERROR "expression in go/defer must be function call, not parenthesized expression"
+	go ((x))        // ERROR "expression in go/defer must be function call, not parenthesized expression"
+	defer (f(x, y)) // ERROR "expression in go/defer must not be parenthesized"
+}
@@ -0,0 +1,38 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test the note for struct types that differ only in field order.
+
+package p
+
+type A struct {
+	a int
+	b string
+}
+
+type B struct {
+	b string
+	a int
+}
+
+func f() {
+	var x struct {
+		a int
+		b string
+	}
+	var y struct {
+		b string
+		a int
+	}
+	x = y      // ERROR "same fields in different order"
+	_ = x == y // ERROR "mismatched types(.|\n)*same fields in different order"
+
+	var a A
+	var b B
+	a = b    // ERROR "structs B and A have the same fields in different order"
+	a = A(b) // ERROR "cannot convert"
+	_ = a
+}
@@ -0,0 +1,35 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that undefined fields and methods suggest close names.
+
+package p
+
+type Inner struct {
+	Address string
+}
+
+func (Inner) Reset() {}
+
+type T struct {
+	Inner
+	Name  string
+	count int
+	Lenx  int
+	Leny  int
+}
+
+func (*T) Close() {}
+
+func f(t T, p *T) {
+	_ = t.Nmae      // ERROR "t.Nmae undefined \(type T has no field or method Nmae, but does have Name\)"
+	_ = p.cuont     // ERROR "has no field or method cuont, but does have count\)"
+	_ = t.Adress    // ERROR "has no field or method Adress, but does have Address\)"
+	_ = p.Closer    // ERROR "has no field or method Closer, but does have Close\)"
+	_ = t.Reste     // ERROR "has no field or method Reste, but does have Reset\)"
+	_ = t.Lenz      // ERROR "has no field or method Lenz\)"
+	_ = t.Unrelated // ERROR "has no field or method Unrelated\)"
+}
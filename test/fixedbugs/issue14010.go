@@ -10,6 +10,6 @@
 package main
 
 func main() {
-	true = false // ERROR "cannot assign to true"
+	true = false // ERROR "cannot assign to constant true"
 	byte = 0     // ERROR "not an expression" "cannot assign to byte"
 }
@@ -12,7 +12,7 @@ func main() {
 	_ = make(T, -1)    // ERROR "negative"
 	_ = make(T, 0.5)   // ERROR "constant 0.5 truncated to integer|non-integer len argument"
 	_ = make(T, 1.0)   // ok
-	_ = make(T, 1<<63) // ERROR "len argument too large"
+	_ = make(T, 1<<63) // ERROR "len argument 9223372036854775808 exceeds maximum int value 9223372036854775807"
 	_ = make(T, 0, -1) // ERROR "negative cap"
 	_ = make(T, 10, 0) // ERROR "len larger than cap"
 }
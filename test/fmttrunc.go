@@ -0,0 +1,21 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that mismatched type errors elide long struct bodies.
+
+package p
+
+var a struct {
+	f0, f1, f2, f3, f4, f5, f6, f7, f8, f9           int
+	f10, f11, f12, f13, f14, f15, f16, f17, f18, f19 int
+	f20, f21, f22, f23, f24, f25, f26, f27, f28, f29 int
+	f30, f31, f32, f33, f34, f35, f36, f37, f38, f39 int
+	f40, f41, f42, f43, f44, f45, f46, f47, f48, f49 int
+}
+
+var b int
+
+var _ = a == b // ERROR "mismatched types struct { f0 int; f1 int; f2 int; f3 int; f4 int; f5 int; f6 int; f7 int; ... \+42 more } and int"
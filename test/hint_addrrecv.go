@@ -0,0 +1,32 @@
+// errorcheck -0 -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint notes taking the address of a value receiver.
+
+package p
+
+type T struct {
+	x int
+}
+
+func (t T) Set(x int) {
+	p := &t // ERROR "address of value receiver; modifications won't affect the caller"
+	p.x = x
+}
+
+func (t T) SetX(x int) {
+	px := &t.x // ERROR "address of value receiver; modifications won't affect the caller"
+	*px = x
+}
+
+func (t *T) SetPtr(x int) {
+	p := &t
+	(*p).x = x
+}
+
+func (t T) Copy(u T) *T {
+	return &u
+}
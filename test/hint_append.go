@@ -0,0 +1,20 @@
+// errorcheck -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint notes append calls with nothing to append.
+
+package p
+
+func two() ([]int, int) { return nil, 0 }
+
+func f(s, t []int) []int {
+	append(s)     // ERROR "append with no elements to add is a no-op" "evaluated but not used"
+	s = append(s) // ERROR "append with no elements to add is a no-op"
+	s = append(s, 1)
+	s = append(s, t...)
+	s = append(two())
+	return s
+}
@@ -0,0 +1,21 @@
+// errorcheck -0 -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint reports array literals with fewer elements
+// than the array bound.
+
+package p
+
+var a = [5]int{1, 2} // ERROR "array literal has 2 of 5 elements; remaining are zero-valued"
+var b = [5]int{1, 2, 3, 4, 5}
+var c = [5]int{}
+var d = [5]int{0: 1, 1: 2}
+var e = [...]int{1, 2}
+var s = []int{1, 2}
+
+func f() {
+	_ = [3]string{"a"} // ERROR "array literal has 1 of 3 elements"
+}
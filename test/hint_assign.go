@@ -0,0 +1,27 @@
+// errorcheck -0 -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint reports variables assigned more than once
+// in a single assignment.
+
+package p
+
+type T struct{ x, y int }
+
+func f() {
+	var a, b int
+	var t T
+	var s []int
+	a, a = 1, 2       // ERROR "a assigned multiple times in assignment"
+	a, b, a = 1, 2, 3 // ERROR "a assigned multiple times in assignment"
+	a, _ = 1, 2
+	_, _ = 1, 2
+	a, b = b, a
+	t.x, t.x = 1, 2 // ERROR "t.x assigned multiple times in assignment"
+	t.x, t.y = 1, 2
+	s[0], s[1] = 1, 2
+	_, _, _ = a, b, t
+}
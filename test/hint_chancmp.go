@@ -0,0 +1,16 @@
+// errorcheck -0 -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint notes comparisons between channel values.
+
+package p
+
+func f(ch1, ch2 chan int, r <-chan int) bool {
+	_ = ch1 == nil
+	_ = nil != ch2
+	_ = ch1 != r      // ERROR "comparing channel identities; channels are equal only if created by the same make"
+	return ch1 == ch2 // ERROR "comparing channel identities"
+}
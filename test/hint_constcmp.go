@@ -0,0 +1,30 @@
+// errorcheck -0 -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint reports comparisons that are always
+// true or always false.
+
+package p
+
+const (
+	x     = 1
+	y     = 2
+	s     = "hello"
+	debug = false
+)
+
+const _ = x < y
+
+func f(v int) {
+	_ = x < x      // ERROR "comparison is always false"
+	_ = len(s) > 0 // ERROR "comparison is always true"
+	_ = x <= y     // ERROR "comparison is always true"
+	_ = debug == false
+	_ = debug != true
+	_ = v < x
+	if x == y { // ERROR "comparison is always false"
+	}
+}
@@ -0,0 +1,31 @@
+// errorcheck -0 -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint reports constant string concatenations
+// longer than 1 MB.
+
+package p
+
+const s0 = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+const s1 = s0 + s0
+const s2 = s1 + s1
+const s3 = s2 + s2
+const s4 = s3 + s3
+const s5 = s4 + s4
+const s6 = s5 + s5
+const s7 = s6 + s6
+const s8 = s7 + s7
+const s9 = s8 + s8
+const s10 = s9 + s9
+const s11 = s10 + s10
+const s12 = s11 + s11
+const s13 = s12 + s12
+const s14 = s13 + s13
+const s15 = s13 + s13 + s13 // ERROR "constant string literal of 1572864 bytes"
+
+var x = s13 + s13 + "!" // ERROR "constant string literal of 1048577 bytes"
+var y = s15 + s15
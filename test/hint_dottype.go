@@ -0,0 +1,22 @@
+// errorcheck -0 -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint reports type assertions to the operand's
+// own interface type.
+
+package p
+
+type I interface {
+	M()
+}
+
+func f(e error, i I, x interface{}) {
+	_ = e.(error) // ERROR "redundant type assertion: e is already error"
+	_, _ = i.(I)  // ERROR "redundant type assertion: i is already I"
+	_ = x.(error)
+	_ = e.(interface{})
+	_ = x.(interface{}) // ERROR "redundant type assertion: x is already interface {}"
+}
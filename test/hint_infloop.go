@@ -0,0 +1,42 @@
+// errorcheck -0 -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint notes code following an infinite loop.
+
+package p
+
+func f() int {
+	for {
+	}
+	return 1 // ERROR "unreachable code after infinite loop"
+}
+
+func g(x int) {
+	if x > 0 {
+		for {
+			if x > 1 {
+				break
+			}
+		}
+		println()
+		for {
+		}
+		println() // ERROR "unreachable code after infinite loop"
+	}
+	switch x {
+	case 1:
+		for {
+		}
+	L:
+		println()
+		goto L
+	}
+	func() {
+		for {
+		}
+		println() // ERROR "unreachable code after infinite loop"
+	}()
+}
@@ -0,0 +1,23 @@
+// errorcheck -0 -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint reports allocating conversions
+// between strings and byte slices inside loops.
+
+package p
+
+func f(b []byte, s string, lines [][]byte) {
+	var out []string
+	for i := 0; i < 10; i++ {
+		out = append(out, string(b)) // ERROR "\[\]byte to string conversion in loop allocates each iteration"
+		b = []byte(s)                // ERROR "string to \[\]byte conversion in loop allocates each iteration"
+	}
+	for _, l := range lines {
+		out = append(out, string(l)) // ERROR "\[\]byte to string conversion in loop"
+	}
+	out = append(out, string(b))
+	_ = out
+}
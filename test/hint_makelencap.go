@@ -0,0 +1,21 @@
+// errorcheck -0 -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint notes make calls whose len and cap are
+// both variables.
+
+package p
+
+const c = 10
+
+func f(a, b int, s []int) {
+	_ = make([]int, a, b) // ERROR "make\(\[\]int, a, b\) panics if a > b; consider checking len against cap"
+	_ = make([]int, a, a)
+	_ = make([]int, a, c)
+	_ = make([]int, a)
+	_ = make([]int, a, len(s))
+	_ = make([]int, 1, 2)
+}
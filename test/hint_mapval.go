@@ -0,0 +1,32 @@
+// errorcheck -0 -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint reports maps whose value type is large.
+
+package p
+
+type big struct {
+	a [32]int64
+}
+
+type small struct {
+	a, b int64
+}
+
+var m1 map[string]big // ERROR "map value type big is large \(256 bytes\); consider map\[string\]\*big"
+var m2 map[string]*big
+var m3 map[string]small
+
+type T struct {
+	m map[int]T
+}
+
+func f() {
+	_ = map[int]big{}           // ERROR "map value type big is large"
+	_ = make(map[int][17]int64) // ERROR "map value type \[17\]int64 is large \(136 bytes\)"
+	_ = make(map[int][16]int64)
+	_ = map[int]small{}
+}
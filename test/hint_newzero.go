@@ -0,0 +1,19 @@
+// errorcheck -0 -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint notes new of zero-size types.
+
+package p
+
+type empty struct{}
+
+func f() {
+	_ = new(struct{}) // ERROR "new\(struct {}\) allocates a zero-size type; all such pointers may be equal"
+	_ = new([0]int)   // ERROR "new\(\[0\]int\) allocates a zero-size type"
+	_ = new(empty)    // ERROR "new\(empty\) allocates a zero-size type"
+	_ = new(int)
+	_ = new([1]int)
+}
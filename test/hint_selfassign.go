@@ -0,0 +1,30 @@
+// errorcheck -0 -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint reports self-assignments.
+
+package p
+
+type T struct {
+	x int
+	p *T
+	a []int
+}
+
+func g() int { return 0 }
+
+func f(t T, p *T, i int, m map[int]int) {
+	i = i           // ERROR "self-assignment of i to i"
+	t.x = t.x       // ERROR "self-assignment of t.x to t.x"
+	p.p.x = p.p.x   // ERROR "self-assignment of p.p.x to p.p.x"
+	*p = *p         // ERROR "self-assignment of \*p to \*p"
+	t.a[i] = t.a[i] // ERROR "self-assignment of t.a\[i\] to t.a\[i\]"
+	i, t.x = i, g() // ERROR "self-assignment of i to i"
+	i, t.x = t.x, i
+	t.a[g()] = t.a[g()]
+	m[i] = m[i]
+	_ = i
+}
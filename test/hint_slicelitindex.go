@@ -0,0 +1,17 @@
+// errorcheck -0 -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint notes constant indexes that are out of range
+// for a slice literal. Such indexes are valid Go and panic at run time.
+
+package p
+
+var _ = []int{1, 2, 3}[5] // ERROR "index 5 out of range for 3-element slice literal; will panic at run time"
+var _ = []int{1, 2, 3}[3] // ERROR "out of range for 3-element slice literal"
+var _ = []int{9: 1}[10]   // ERROR "out of range for 10-element slice literal"
+var _ = []int{1, 2, 3}[2]
+var _ = []int{9: 1}[9]
+var _ = []string{"a"}[1] // ERROR "out of range for 1-element slice literal"
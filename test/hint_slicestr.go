@@ -0,0 +1,22 @@
+// errorcheck -0 -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint reports slices of constant strings that
+// could split a multibyte UTF-8 sequence.
+
+package p
+
+const s = "héllo, 世界"
+const ascii = "hello"
+
+func f(i int) {
+	_ = s[1:2] // ERROR "slicing s at byte 2 splits a multibyte UTF-8 sequence"
+	_ = s[0:1]
+	_ = s[1:3]
+	_ = s[:i] // ERROR "slice bounds of s are byte offsets, but it contains multibyte UTF-8 sequences"
+	_ = ascii[1:2]
+	_ = ascii[:i]
+}
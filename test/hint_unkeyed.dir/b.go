@@ -0,0 +1,19 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package b
+
+import "./a"
+
+type U struct {
+	X, Y int
+}
+
+var (
+	_ = a.T{1, 2}  // ERROR "a.T literal uses unkeyed fields; use field names"
+	_ = &a.T{1, 2} // ERROR "a.T literal uses unkeyed fields; use field names"
+	_ = a.T{X: 1, Y: 2}
+	_ = []a.T{{1, 2}, {3, 4}}
+	_ = U{1, 2}
+)
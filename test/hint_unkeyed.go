@@ -0,0 +1,9 @@
+// errorcheckdir -0 -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint notes unkeyed literals of imported struct types.
+
+package ignored
@@ -0,0 +1,53 @@
+// errorcheck -0 -d=hint
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=hint notes code following a terminating statement.
+
+package p
+
+func f(x int) int {
+	if x > 0 {
+		return 1
+		x++ // ERROR "unreachable code after return"
+	}
+	{
+		panic("x")
+	}
+	println() // ERROR "unreachable code after panic"
+	return 0
+}
+
+func g(x int) {
+	switch x {
+	case 1:
+		panic(x)
+		println() // ERROR "unreachable code after panic"
+	case 2:
+		goto L
+		println() // ERROR "unreachable code after goto"
+	default:
+		if x > 3 {
+			return
+		} else {
+			panic(x)
+		}
+		println() // ERROR "unreachable code$"
+	}
+	goto L
+L:
+	println()
+}
+
+func h(c chan int) int {
+	select {
+	case <-c:
+		return 1
+		println() // ERROR "unreachable code after return"
+	}
+	select {}
+	println() // ERROR "unreachable code$"
+	return 0
+}
@@ -0,0 +1,43 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that duplicate keys, indices, and fields in composite
+// literals report where the first one was set.
+
+package p
+
+const (
+	A = 1
+	B = 1
+)
+
+type T struct{ x, y int }
+
+var _ = map[int]int{
+	A: 1,
+	B: 2, // ERROR "duplicate key B in map literal .previously set at .*litdup.go:20."
+}
+
+var _ = map[string]int{
+	"a": 1,
+	"a": 2, // ERROR "duplicate key .a. in map literal .previously set at .*litdup.go:25."
+}
+
+var _ = []int{
+	1: 1,
+	0: 2,
+	1: 3, // ERROR "duplicate index in array literal: 1 .previously set at .*litdup.go:30."
+}
+
+var _ = []int{
+	5,
+	0: 2, // ERROR "duplicate index in array literal: 0 .previously set at .*litdup.go:36."
+}
+
+var _ = T{
+	x: 1,
+	x: 2, // ERROR "duplicate field name in struct literal: x .previously set at .*litdup.go:41."
+}
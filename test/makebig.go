@@ -0,0 +1,14 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that make reports constant sizes that do not fit in int.
+
+package p
+
+func f() {
+	_ = make([]int, 1<<63)    // ERROR "len argument 9223372036854775808 exceeds maximum int value 9223372036854775807 in make\(\[\]int\)"
+	_ = make([]int, 0, 1<<63) // ERROR "cap argument 9223372036854775808 exceeds maximum int value 9223372036854775807 in make\(\[\]int\)"
+}
@@ -0,0 +1,29 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that make reports non-comparable map key types that were
+// not already reported when the map type was built.
+
+package p
+
+type K []int
+
+// The key of M is still being defined when M is built,
+// so the key check is postponed and make checks again.
+type F struct {
+	s []int
+	m M
+}
+
+type M map[F]int // ERROR "invalid map key type F$"
+
+func f() {
+	_ = make(map[[]int]int) // ERROR "invalid map key type \[\]int$"
+	_ = make(map[K]int, 10) // ERROR "invalid map key type K$"
+	_ = make(map[[2]int]int)
+	_ = make(map[string]int, 10)
+	_ = make(M) // ERROR "invalid map key type F \(not comparable\)"
+}
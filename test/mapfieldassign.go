@@ -0,0 +1,27 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that assigning to a field of a map element is reported
+// as such.
+
+package p
+
+type T struct {
+	x int
+	a [2]int
+	u struct{ y int }
+}
+
+func f(m map[string]T, pm map[string]*T) {
+	m["k"].x = 1    // ERROR "cannot assign to struct field m\[.k.\].x in map \(assign the whole map element instead\)"
+	m["k"].u.y = 2  // ERROR "cannot assign to struct field m\[.k.\].u.y in map"
+	m["k"].a[0] = 3 // ERROR "cannot assign to m\[.k.\].a\[0\]"
+	pm["k"].x = 4
+	(*pm["k"]).x = 5
+	t := m["k"]
+	t.x = 6
+	m["k"] = t
+}
@@ -0,0 +1,18 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that assigning a map index to more than two variables
+// explains how many values a map index yields.
+
+package p
+
+func f(m map[string]int) {
+	v, ok, extra := m["a"] // ERROR "assignment count mismatch: 3 = 1: map index returns at most 2 values \(value, ok\)"
+	var a, b, c int
+	a, b, c = m["b"] // ERROR "map index returns at most 2 values"
+	x, y := m["c"]
+	_, _, _, _, _, _, _, _ = v, ok, extra, a, b, c, x, y
+}
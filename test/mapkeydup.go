@@ -0,0 +1,31 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that map literal keys given by named constants with
+// equal values are reported as duplicates.
+
+package p
+
+type K int
+
+const (
+	A K = 1
+	B K = 1
+	S   = "s"
+	T   = "s"
+	C   = true
+	D   = !false
+)
+
+var x = 1
+
+var (
+	_ = map[K]int{A: 1, B: 2}                 // ERROR "duplicate key B in map literal"
+	_ = map[string]int{S: 1, T: 2}            // ERROR "duplicate key T in map literal"
+	_ = map[bool]int{C: 1, D: 2}              // ERROR "duplicate key D in map literal"
+	_ = map[interface{}]int{A: 1, 1: 2, B: 3} // ERROR "duplicate key B in map literal"
+	_ = map[int]int{x: 1, x: 2}
+)
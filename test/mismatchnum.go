@@ -0,0 +1,18 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that mismatched numeric types suggest a conversion.
+
+package p
+
+func f(i int, i8 int8, i64 int64, f32 float32, f64 float64, s string) {
+	_ = i8 + i64  // ERROR "mismatched types int8 and int64\) \(use a conversion such as int64\(i8\)\)"
+	_ = i64 < i8  // ERROR "mismatched types int64 and int8\) \(use a conversion such as int64\(i8\)\)"
+	_ = f32 * f64 // ERROR "use a conversion such as float64\(f32\)"
+	_ = i == f64  // ERROR "use a conversion such as float64\(i\)"
+	_ = i + s     // ERROR "mismatched types int and string\)$"
+	_ = i + 1.0
+}
@@ -0,0 +1,37 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that assigning a type missing several methods of an
+// interface lists all of them.
+
+package p
+
+type I interface {
+	A()
+	B()
+	C()
+}
+
+type T struct{}
+
+func (T) B() {}
+
+type U struct{}
+
+func (U) A() {}
+func (U) B() {}
+
+type J interface {
+	B()
+}
+
+var j J
+
+var (
+	_ I = T{} // ERROR "T does not implement I \(missing methods: A, C\)"
+	_ I = U{} // ERROR "U does not implement I \(missing C method\)"
+	_ I = j   // ERROR "J does not implement I \(missing methods: A, C\)"
+)
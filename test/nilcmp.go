@@ -0,0 +1,16 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test the error for comparing two untyped nil values.
+
+package p
+
+var p *int
+
+var _ = nil == nil // ERROR "invalid operation: nil == nil \(comparison of two untyped nil values\)"
+var _ = nil != nil // ERROR "comparison of two untyped nil values"
+var _ = p == nil
+var _ = nil != p
@@ -0,0 +1,31 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that interfaces embedding themselves,
+// directly or through other interfaces, are rejected.
+
+package p
+
+type I interface {
+	I // ERROR "invalid recursive interface I"
+}
+
+type J interface {
+	K
+	M()
+}
+
+type K interface {
+	J // ERROR "invalid recursive interface J"
+}
+
+type L interface {
+	N
+}
+
+type N interface {
+	M()
+}
@@ -0,0 +1,16 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test the error for receiving from a send-only channel.
+
+package p
+
+func f(ch chan<- int, chs []chan<- string) {
+	x := <-ch     // ERROR "cannot receive from send-only channel ch \(declared chan<- int\)"
+	y, ok := <-ch // ERROR "cannot receive from send-only channel ch \(declared chan<- int\)"
+	<-chs[0]      // ERROR "cannot receive from send-only channel chs\[0\] \(declared chan<- string\)"
+	_, _, _ = x, y, ok
+}
@@ -0,0 +1,30 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test returning the results of a call whose
+// result types do not match the function's.
+
+package p
+
+func two() (int, string)     { return 0, "" }
+func three() (int, int, int) { return 0, 0, 0 }
+func ints() (int, int)       { return 0, 0 }
+
+func f() (int, int) {
+	return two() // ERROR "cannot return result of two\(\) \(type \(int, string\)\) from function returning \(int, int\)"
+}
+
+func g() (int, int) {
+	return three() // ERROR "too many arguments to return"
+}
+
+func h() (int, int) {
+	return ints()
+}
+
+func i() (int, interface{}) {
+	return two()
+}
@@ -0,0 +1,18 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that a keyed struct literal cannot set a blank field.
+
+package p
+
+type T struct {
+	_ int
+	a int
+}
+
+var x = T{_: 1, a: 2} // ERROR "cannot use _ as a struct field name in literal"
+var y = T{a: 2}
+var z = &T{_: 3} // ERROR "cannot use _ as a struct field name in literal"
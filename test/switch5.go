@@ -0,0 +1,30 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Verify that switching on a non-comparable value is rejected.
+// Does not compile.
+
+package p
+
+type S struct {
+	a []int
+}
+
+func f() {
+	var s, s1 []int
+	switch s {
+	case nil:
+	case s1: // ERROR "can only compare slice s to nil"
+	}
+
+	var st S
+	switch st { // ERROR "cannot switch on st \(type S\) \(not comparable: struct containing \[\]int cannot be compared\)"
+	}
+
+	var ar [2][]int
+	switch ar { // ERROR "cannot switch on ar \(type \[2\]\[\]int\) \(not comparable\)"
+	}
+}
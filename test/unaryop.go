@@ -0,0 +1,19 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test errors for unary operators applied to unsuitable types.
+
+package p
+
+func f(s string, i int, b bool, x float64) {
+	_ = -s // ERROR "invalid operation: - on string \(unary minus requires a numeric type\)"
+	_ = +s // ERROR "invalid operation: \+ on string \(unary plus requires a numeric type\)"
+	_ = !i // ERROR "invalid operation: ! on int \(logical not requires a boolean type\)"
+	_ = ^x // ERROR "invalid operation: \^ on float64 \(bitwise complement requires an integer type\)"
+	_ = -i
+	_ = !b
+	_ = ^i
+}
@@ -0,0 +1,30 @@
+// errorcheck
+
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Verify that "evaluated but not used" errors name
+// the enclosing function.
+
+package p
+
+type T int
+
+func f(x int) {
+	x + 1 // ERROR "x \+ 1 evaluated but not used in f$"
+}
+
+func (T) m(x int) {
+	x * 2 // ERROR "x \* 2 evaluated but not used in T.m$"
+}
+
+func g() {
+	func(x int) {
+		x - 1 // ERROR "x - 1 evaluated but not used in g$"
+	}(0)
+}
+
+var _ = func(x int) {
+	x / 2 // ERROR "x / 2 evaluated but not used$"
+}